@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+// reverseSets lists the attributes that mirror references in another table and are maintained by the importers.
+var reverseSets = []importer.ReverseSet{
+	{
+		TableName:           "Resistances",
+		Attribute:           "Ingredients",
+		ReferringTableName:  "Ingredients",
+		ReferringAttributes: []string{"ResistanceId", "ResistanceIds"},
+		IgnoredIds:          []string{strconv.Itoa(picolApiV1.NullResistanceId)},
+	},
+}
+
+func checkIntegrity(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("check-integrity", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "Rebuild each mismatched set from the references it mirrors, e.g. Resistances.Ingredients from the resistance ids of ingredients.")
+	concurrency := flags.Int("concurrency", importer.DefaultConcurrency, "Number of concurrent write requests with -fix.")
+	help := flags.Bool("help", false, "Show help.")
+
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Check that the sets maintained by the importers match the references they mirror.\n")
+		fmt.Fprintf(out, "Usage: %s check-integrity [options]\n", os.Args[0])
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if *help {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return 0
+	}
+
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", flags.Arg(0))
+		flags.Usage()
+		return 1
+	}
+
+	config := importer.Config{
+		AWSConfig:   CtxGetAWSConfig(ctx),
+		TablePrefix: CtxGetDynamoDBTablePrefix(ctx),
+	}
+
+	result := 0
+	for _, set := range reverseSets {
+		name := fmt.Sprintf("%s.%s", set.TableName, set.Attribute)
+
+		check, err := importer.CheckReverseSet(ctx, config, set, *fix, *concurrency)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking %s: %s\n", name, err)
+			result = 1
+			continue
+		}
+
+		if len(check.Mismatches) == 0 {
+			fmt.Printf("%s: OK\n", name)
+			continue
+		}
+
+		fmt.Printf("%s: %d mismatch(es)\n", name, len(check.Mismatches))
+		for _, mismatch := range check.Mismatches {
+			fmt.Printf("  %s\n", mismatch)
+		}
+
+		if !*fix {
+			result = 1
+			continue
+		}
+
+		fmt.Printf("%s: rebuilt %d set(s)\n", name, check.Updated)
+		if check.Dangling > 0 {
+			fmt.Printf("%s: %d reference(s) to missing items cannot be fixed by rebuilding the sets\n", name, check.Dangling)
+			result = 1
+		}
+	}
+
+	return result
+}
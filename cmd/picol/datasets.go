@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// datasetFilenameRegexp matches dataset filenames of the form <entity>-YYYY-MM-DD.json.
+var datasetFilenameRegexp = regexp.MustCompile(`^([a-z]+(?:-[a-z]+)*)-(\d{4}-\d{2}-\d{2})\.json$`)
+
+// latestDatasetFile returns the path of the newest dataset file for the given entity in dir, e.g.
+// datasets/applications-2023-10-17.json for entity "applications".
+func latestDatasetFile(dir string, entity string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	latestName := ""
+	latestDate := ""
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := datasetFilenameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil || match[1] != entity {
+			continue
+		}
+
+		// Dates are ISO 8601, so they sort lexically.
+		if match[2] > latestDate {
+			latestName = entry.Name()
+			latestDate = match[2]
+		}
+	}
+
+	if latestName == "" {
+		return "", fmt.Errorf("no %s dataset found in %s", entity, dir)
+	}
+
+	return filepath.Join(dir, latestName), nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+)
+
+// enumValue is the common shape of a PICOL enumeration value, used to compare the Go constants in ddbmodel against
+// the datasets.
+type enumValue struct {
+	Id   int
+	Code string
+	Name string
+}
+
+// compareEnum compares the Go enumeration values against the values from a dataset and returns a description of each
+// difference. An empty result means the two agree.
+func compareEnum(kind string, goValues []enumValue, dataValues []enumValue) []string {
+	goById := make(map[int]enumValue, len(goValues))
+	for _, v := range goValues {
+		goById[v.Id] = v
+	}
+
+	dataById := make(map[int]enumValue, len(dataValues))
+	for _, v := range dataValues {
+		dataById[v.Id] = v
+	}
+
+	ids := make([]int, 0, len(goById)+len(dataById))
+	for id := range goById {
+		ids = append(ids, id)
+	}
+	for id := range dataById {
+		if _, found := goById[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var diffs []string
+	for _, id := range ids {
+		goValue, inGo := goById[id]
+		dataValue, inData := dataById[id]
+
+		switch {
+		case !inData:
+			diffs = append(diffs, fmt.Sprintf("%s %d: defined in Go (Code=%q, Name=%q) but missing from dataset", kind, id, goValue.Code, goValue.Name))
+		case !inGo:
+			diffs = append(diffs, fmt.Sprintf("%s %d: present in dataset (Code=%q, Name=%q) but not defined in Go", kind, id, dataValue.Code, dataValue.Name))
+		default:
+			if goValue.Code != dataValue.Code {
+				diffs = append(diffs, fmt.Sprintf("%s %d: Code mismatch: Go=%q dataset=%q", kind, id, goValue.Code, dataValue.Code))
+			}
+			if goValue.Name != dataValue.Name {
+				diffs = append(diffs, fmt.Sprintf("%s %d: Name mismatch: Go=%q dataset=%q", kind, id, goValue.Name, dataValue.Name))
+			}
+		}
+	}
+
+	return diffs
+}
+
+func applicationEnumValues() []enumValue {
+	var values []enumValue
+	for _, a := range ddbmodel.AllApplications() {
+		values = append(values, enumValue{Id: int(a), Code: string(a.Code()), Name: a.Name()})
+	}
+	return values
+}
+
+func intendedUserEnumValues() []enumValue {
+	var values []enumValue
+	for _, iu := range ddbmodel.AllIntendedUsers() {
+		values = append(values, enumValue{Id: int(iu), Code: string(iu.Code()), Name: iu.Name()})
+	}
+	return values
+}
+
+func signalWordEnumValues() []enumValue {
+	var values []enumValue
+	for _, sw := range ddbmodel.AllSignalWords() {
+		values = append(values, enumValue{Id: int(sw), Code: string(sw.Code()), Name: sw.Name()})
+	}
+	return values
+}
+
+func stateEnumValues() []enumValue {
+	var values []enumValue
+	for _, s := range ddbmodel.AllStates() {
+		values = append(values, enumValue{Id: int(s), Name: s.Name()})
+	}
+	return values
+}
+
+func applicationDataValue(a picolApiV1.Application) enumValue {
+	return enumValue{Id: a.Id, Code: a.Code, Name: a.Name}
+}
+
+func intendedUserDataValue(iu picolApiV1.IntendedUser) enumValue {
+	return enumValue{Id: iu.Id, Code: iu.Code, Name: iu.Name}
+}
+
+func signalWordDataValue(sw picolApiV1.SignalWord) enumValue {
+	return enumValue{Id: sw.Id, Code: sw.Code, Name: sw.Name}
+}
+
+func stateDataValue(s picolApiV1.State) enumValue {
+	return enumValue{Id: s.Id, Name: s.Name}
+}
+
+// enumDataValues converts dataset records using the given per-record conversion.
+func enumDataValues[T any](records []T, dataValue func(T) enumValue) []enumValue {
+	values := make([]enumValue, len(records))
+	for i, record := range records {
+		values[i] = dataValue(record)
+	}
+	return values
+}
+
+// enumCheck compares one ddbmodel enumeration against its dataset.
+type enumCheck struct {
+	// Dataset entity name, e.g. "applications".
+	Entity string
+
+	// Check returns the differences between the Go definitions and the dataset in the given file.
+	Check func(filename string) ([]string, error)
+}
+
+// enumChecks lists every ddbmodel enumeration that is backed by a dataset.
+var enumChecks = []enumCheck{
+	{
+		Entity: "applications",
+		Check: func(filename string) ([]string, error) {
+			return checkEnumFile(filename, "application", applicationEnumValues, applicationDataValue)
+		},
+	},
+	{
+		Entity: "intended-users",
+		Check: func(filename string) ([]string, error) {
+			return checkEnumFile(filename, "intended user", intendedUserEnumValues, intendedUserDataValue)
+		},
+	},
+	{
+		Entity: "signal-words",
+		Check: func(filename string) ([]string, error) {
+			return checkEnumFile(filename, "signal word", signalWordEnumValues, signalWordDataValue)
+		},
+	},
+	{
+		Entity: "states",
+		Check: func(filename string) ([]string, error) {
+			return checkEnumFile(filename, "state", stateEnumValues, stateDataValue)
+		},
+	},
+}
+
+// checkEnumFile decodes a dataset file and compares it against the Go definitions.
+func checkEnumFile[T any](filename string, kind string, goValues func() []enumValue, dataValue func(T) enumValue) ([]string, error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var records picolApiV1.Response[T]
+	err = json.NewDecoder(fd).Decode(&records)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", filename, err)
+	}
+	if records.Error {
+		return nil, fmt.Errorf("error decoding %s: %w", filename, &picolApiV1.ErrorResponse{Message: records.Message})
+	}
+
+	return compareEnum(kind, goValues(), enumDataValues(records.Data, dataValue)), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const datasetsDir = "../../datasets"
+
+func TestEnumChecksAgreeWithDatasets(t *testing.T) {
+	for _, check := range enumChecks {
+		filename, err := latestDatasetFile(datasetsDir, check.Entity)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		diffs, err := check.Check(filename)
+		if err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+		for _, diff := range diffs {
+			t.Errorf("%s: %s", filename, diff)
+		}
+	}
+}
+
+func TestCheckEnumFileReportsPlantDipMismatch(t *testing.T) {
+	filename, err := latestDatasetFile(datasetsDir, "applications")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The definitions before verify-enums existed: Plant Dip was id 4 with code D, and Seed Treatment was id 5.
+	oldValues := func() []enumValue {
+		return []enumValue{
+			{Id: 1, Code: "A", Name: "AERIAL"},
+			{Id: 2, Code: "G", Name: "GROUND"},
+			{Id: 3, Code: "I", Name: "IRRIGATION"},
+			{Id: 4, Code: "D", Name: "PLANT DIP"},
+			{Id: 5, Code: "S", Name: "SEED TREATMENT"},
+		}
+	}
+
+	diffs, err := checkEnumFile(filename, "application", oldValues, applicationDataValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`application 4: Code mismatch: Go="D" dataset="S"`,
+		`application 4: Name mismatch: Go="PLANT DIP" dataset="SEED TREATMENT"`,
+		`application 5: Code mismatch: Go="S" dataset="P"`,
+		`application 5: Name mismatch: Go="SEED TREATMENT" dataset="PLANT DIP"`,
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("got diffs %q, want %q", diffs, want)
+	}
+}
+
+func TestCompareEnumReportsMissingValues(t *testing.T) {
+	goValues := []enumValue{{Id: 1, Code: "A", Name: "AERIAL"}, {Id: 2, Code: "G", Name: "GROUND"}}
+	dataValues := []enumValue{{Id: 1, Code: "A", Name: "AERIAL"}, {Id: 3, Code: "I", Name: "IRRIGATION"}}
+
+	diffs := compareEnum("application", goValues, dataValues)
+
+	want := []string{
+		`application 2: defined in Go (Code="G", Name="GROUND") but missing from dataset`,
+		`application 3: present in dataset (Code="I", Name="IRRIGATION") but not defined in Go`,
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("got diffs %q, want %q", diffs, want)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/corbaltcode/picol/internal/ddbtest"
+)
+
+// newFakeDynamoDB starts a fake DynamoDB endpoint and returns a context that points the subcommands at it, with the
+// table prefix "T".
+func newFakeDynamoDB(t *testing.T) (context.Context, *ddbtest.Fake) {
+	fake, config := ddbtest.New(t)
+
+	ctx := context.WithValue(context.Background(), PicolCtxDynamoDBTablePrefix, "T")
+	ctx = context.WithValue(ctx, PicolCtxAWSConfig, config)
+	return ctx, fake
+}
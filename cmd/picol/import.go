@@ -0,0 +1,17 @@
+package main
+
+import (
+	"context"
+
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+// runImport runs an import subcommand using the AWS configuration and table prefix from the context.
+func runImport[T any](ctx context.Context, args []string, d importer.Descriptor[T]) int {
+	config := importer.Config{
+		AWSConfig:   CtxGetAWSConfig(ctx),
+		TablePrefix: CtxGetDynamoDBTablePrefix(ctx),
+	}
+
+	return importer.Run(ctx, config, d, args)
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+// importEntity describes a dataset entity that can be imported by import-all.
+type importEntity struct {
+	// Dataset entity name, e.g. "ingredients" for ingredients-2023-10-17.json.
+	Entity string
+
+	// Exec runs the import subcommand for the entity.
+	Exec func(ctx context.Context, args []string) int
+
+	// Entities that must be imported before this one.
+	DependsOn []string
+}
+
+// importEntities lists every importable entity and its relationships to other entities.
+var importEntities = []importEntity{
+	{Entity: "applications", Exec: importApplications},
+	{Entity: "crops", Exec: importCrops},
+	{Entity: "ingredients", Exec: importIngredients, DependsOn: []string{"resistances"}},
+	{Entity: "intended-users", Exec: importIntendedUsers},
+	{
+		Entity:    "labels",
+		Exec:      importLabels,
+		DependsOn: []string{"crops", "ingredients", "intended-users", "pesticide-types", "pests", "registrants", "signal-words", "states"},
+	},
+	{Entity: "pesticide-types", Exec: importPesticideTypes},
+	{Entity: "pests", Exec: importPests},
+	{Entity: "registrants", Exec: importRegistrants},
+	{Entity: "resistance-sources", Exec: importResistanceSources},
+	{Entity: "resistances", Exec: importResistances, DependsOn: []string{"resistance-sources"}},
+	{Entity: "signal-words", Exec: importSignalWords},
+	{Entity: "states", Exec: importStates},
+}
+
+// importResult records the outcome of importing one entity.
+type importResult struct {
+	Entity   string        `json:"entity"`
+	Filename string        `json:"filename"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"-"`
+
+	// Report is the report written by the import subcommand, if it ran.
+	Report *importer.Report `json:"report,omitempty"`
+}
+
+// importAllReport is the consolidated report written by import-all -report.
+type importAllReport struct {
+	Status          string         `json:"status"`
+	DurationSeconds float64        `json:"durationSeconds"`
+	Imports         []importResult `json:"imports"`
+}
+
+func importAll(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("import-all", flag.ExitOnError)
+	allowUpdate := flags.Bool("allow-update", false, "Allow updating existing records.")
+	concurrency := flags.Int("concurrency", importer.DefaultConcurrency, "Number of concurrent write requests per import.")
+	dryRun := flags.Bool("dry-run", false, "Show what each import would change without writing anything.")
+	diffFormat := flags.String("diff-format", "text", "Format of the dry-run output: text or json.")
+	resume := flags.Bool("resume", false, "Resume each import from the checkpoint of an earlier interrupted run.")
+	strict := flags.Bool("strict", false, "Treat records that violate the documented field constraints as failures.")
+	skipSourceCheck := flags.Bool("skip-source-check", false, "Import resistances without checking them against the resistance sources, e.g. when the directory has no resistance-sources file and none are stored.")
+	reportFile := flags.String("report", "", "Write a consolidated JSON report of all imports to this file, or to standard output if \"-\".")
+	help := flags.Bool("help", false, "Show help.")
+
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Import the newest <entity>-YYYY-MM-DD.json file for every entity in a directory, in dependency order.\n")
+		fmt.Fprintf(out, "Usage: %s import-all [options] <directory>\n", os.Args[0])
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if *help {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return 0
+	}
+
+	if !importer.ValidDiffFormat(*diffFormat) {
+		fmt.Fprintf(os.Stderr, "Unknown diff format: %s\n", *diffFormat)
+		flags.Usage()
+		return 1
+	}
+
+	if *reportFile == "-" && *dryRun {
+		fmt.Fprintf(os.Stderr, "-report - cannot be used with -dry-run, which writes the diffs to standard output.\n")
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "No directory specified.\n")
+		flags.Usage()
+		return 1
+	}
+
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", args[1])
+		flags.Usage()
+		return 1
+	}
+
+	dir := args[0]
+	filenames := map[string]string{}
+	for _, entity := range importEntities {
+		filename, err := latestDatasetFile(dir, entity.Entity)
+		if err != nil {
+			continue
+		}
+		filenames[entity.Entity] = filename
+	}
+
+	if len(filenames) == 0 {
+		fmt.Fprintf(os.Stderr, "No dataset files found in %s.\n", dir)
+		return 1
+	}
+
+	order, err := importOrder(importEntities)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error ordering imports: %s\n", err)
+		return 1
+	}
+
+	// Each import writes its own report to a temporary directory; they are combined afterwards.
+	var reportDir string
+	if *reportFile != "" {
+		reportDir, err = os.MkdirTemp("", "picol-import-all-")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating report directory: %s\n", err)
+			return 1
+		}
+		defer os.RemoveAll(reportDir)
+	}
+
+	start := time.Now()
+	results, failed := runImports(order, filenames, func(entity importEntity, filename string) importResult {
+		subcommandArgs := []string{fmt.Sprintf("-concurrency=%d", *concurrency)}
+		if *allowUpdate {
+			subcommandArgs = append(subcommandArgs, "-allow-update")
+		}
+		if *dryRun {
+			subcommandArgs = append(subcommandArgs, "-dry-run", "-diff-format="+*diffFormat)
+		}
+		if *resume {
+			subcommandArgs = append(subcommandArgs, "-resume")
+		}
+		if *strict {
+			subcommandArgs = append(subcommandArgs, "-strict")
+		}
+		if *skipSourceCheck && entity.Entity == "resistances" {
+			subcommandArgs = append(subcommandArgs, "-skip-source-check")
+		}
+		var entityReportFile string
+		if reportDir != "" {
+			entityReportFile = filepath.Join(reportDir, entity.Entity+".json")
+			subcommandArgs = append(subcommandArgs, "-report="+entityReportFile)
+		}
+		subcommandArgs = append(subcommandArgs, filename)
+
+		fmt.Fprintf(os.Stderr, "Importing %s from %s\n", entity.Entity, filename)
+		var result importResult
+		start := time.Now()
+		exitCode := entity.Exec(ctx, subcommandArgs)
+		result.Duration = time.Since(start)
+
+		if exitCode == 0 {
+			result.Status = "ok"
+		} else {
+			result.Status = "failed"
+		}
+
+		if entityReportFile != "" {
+			report, err := readImportReport(entityReportFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s report: %s\n", entity.Entity, err)
+			}
+			result.Report = report
+		}
+
+		return result
+	})
+
+	// Keep standard output machine-readable when emitting JSON diffs or reports.
+	summaryOut := os.Stdout
+	if (*dryRun && *diffFormat == "json") || *reportFile == "-" {
+		summaryOut = os.Stderr
+	}
+
+	fmt.Fprintf(summaryOut, "\nImport summary:\n")
+	for _, result := range results {
+		fmt.Fprintf(summaryOut, "  %-16s %8s  %-44s %s\n", result.Entity, result.Duration.Round(time.Millisecond), result.Filename, result.Status)
+	}
+
+	if *reportFile != "" {
+		report := importAllReport{Status: importer.ReportStatusOK, DurationSeconds: time.Since(start).Seconds(), Imports: results}
+		if len(failed) > 0 {
+			report.Status = importer.ReportStatusFailed
+		}
+
+		err = writeJSONFile(*reportFile, report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %s\n", err)
+			return 1
+		}
+	}
+
+	if len(failed) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// readImportReport reads a report written by an import subcommand.
+func readImportReport(filename string) (*importer.Report, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var report importer.Report
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// writeJSONFile writes v as indented JSON to filename, or to standard output if filename is "-".
+func writeJSONFile(filename string, v any) error {
+	out := os.Stdout
+	if filename != "-" {
+		fd, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		out = fd
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// runImports imports the entities that have a file, in order, with runImport, which returns the result of a single
+// import. An entity that depends on an entity that failed, or was itself skipped, is skipped. It returns the result of
+// every entity that has a file and the set of entities that did not import successfully.
+func runImports(order []importEntity, filenames map[string]string, runImport func(entity importEntity, filename string) importResult) ([]importResult, map[string]bool) {
+	failed := map[string]bool{}
+	var results []importResult
+
+	for _, entity := range order {
+		filename, found := filenames[entity.Entity]
+		if !found {
+			continue
+		}
+
+		var result importResult
+		if status := skippedStatus(entity, failed); status != "" {
+			result.Status = status
+		} else {
+			result = runImport(entity, filename)
+		}
+		result.Entity = entity.Entity
+		result.Filename = filename
+
+		if result.Status != "ok" {
+			failed[entity.Entity] = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results, failed
+}
+
+// skippedStatus returns the status of an entity that is skipped because an entity it depends on did not import
+// successfully, e.g. "skipped (resistances failed)", or "" if it can be imported.
+func skippedStatus(entity importEntity, failed map[string]bool) string {
+	for _, dependency := range entity.DependsOn {
+		if failed[dependency] {
+			return fmt.Sprintf("skipped (%s failed)", dependency)
+		}
+	}
+	return ""
+}
+
+// importOrder returns the entities sorted so that every entity comes after the entities it depends on. Entities with
+// no ordering constraint between them are sorted by name.
+func importOrder(entities []importEntity) ([]importEntity, error) {
+	byName := make(map[string]importEntity, len(entities))
+	for _, entity := range entities {
+		byName[entity.Entity] = entity
+	}
+
+	remaining := make(map[string]int, len(entities))
+	dependents := map[string][]string{}
+	for _, entity := range entities {
+		remaining[entity.Entity] = len(entity.DependsOn)
+		for _, dependency := range entity.DependsOn {
+			if _, found := byName[dependency]; !found {
+				return nil, fmt.Errorf("%s depends on unknown entity %s", entity.Entity, dependency)
+			}
+			dependents[dependency] = append(dependents[dependency], entity.Entity)
+		}
+	}
+
+	var ready []string
+	for name, count := range remaining {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	var order []importEntity
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, byName[name])
+
+		for _, dependent := range dependents[name] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(entities) {
+		return nil, fmt.Errorf("dependency cycle among entities")
+	}
+
+	return order, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// entityNames returns the names of entities in order.
+func entityNames(entities []importEntity) []string {
+	var names []string
+	for _, entity := range entities {
+		names = append(names, entity.Entity)
+	}
+	return names
+}
+
+func TestImportOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []importEntity
+		want     []string
+		wantErr  string
+	}{
+		{
+			"independent entities by name",
+			[]importEntity{{Entity: "pests"}, {Entity: "crops"}, {Entity: "states"}},
+			[]string{"crops", "pests", "states"},
+			"",
+		},
+		{
+			"chain",
+			[]importEntity{{Entity: "ingredients", DependsOn: []string{"resistances"}}, {Entity: "resistances", DependsOn: []string{"resistance-sources"}}, {Entity: "resistance-sources"}},
+			[]string{"resistance-sources", "resistances", "ingredients"},
+			"",
+		},
+		{
+			"dependents after every dependency",
+			[]importEntity{{Entity: "labels", DependsOn: []string{"crops", "pests"}}, {Entity: "pests"}, {Entity: "crops"}, {Entity: "applications"}},
+			[]string{"applications", "crops", "pests", "labels"},
+			"",
+		},
+		{
+			"ready entities by name",
+			[]importEntity{{Entity: "zebras", DependsOn: []string{"crops"}}, {Entity: "pests"}, {Entity: "crops"}},
+			[]string{"crops", "pests", "zebras"},
+			"",
+		},
+		{
+			"cycle",
+			[]importEntity{{Entity: "crops", DependsOn: []string{"pests"}}, {Entity: "pests", DependsOn: []string{"crops"}}, {Entity: "states"}},
+			nil,
+			"dependency cycle among entities",
+		},
+		{
+			"self-dependency",
+			[]importEntity{{Entity: "crops", DependsOn: []string{"crops"}}},
+			nil,
+			"dependency cycle among entities",
+		},
+		{
+			"unknown dependency",
+			[]importEntity{{Entity: "labels", DependsOn: []string{"crops"}}},
+			nil,
+			"labels depends on unknown entity crops",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order, err := importOrder(test.entities)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("got order %q and error %v, want error %q", entityNames(order), err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := entityNames(order); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got order %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestImportOrderOfImportEntities(t *testing.T) {
+	order, err := importOrder(importEntities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	position := map[string]int{}
+	for i, entity := range order {
+		position[entity.Entity] = i
+	}
+	for _, entity := range importEntities {
+		for _, dependency := range entity.DependsOn {
+			if position[dependency] > position[entity.Entity] {
+				t.Errorf("%s is imported before %s, which it depends on", entity.Entity, dependency)
+			}
+		}
+	}
+}
+
+func TestRunImportsSkipsDependentsOfFailedImports(t *testing.T) {
+	entities := []importEntity{
+		{Entity: "crops"},
+		{Entity: "ingredients", DependsOn: []string{"resistances"}},
+		{Entity: "labels", DependsOn: []string{"crops", "ingredients"}},
+		{Entity: "resistance-sources"},
+		{Entity: "resistances", DependsOn: []string{"resistance-sources"}},
+	}
+	order, err := importOrder(entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		missing []string
+		failing []string
+		want    map[string]string
+	}{
+		{
+			"all imported",
+			nil,
+			nil,
+			map[string]string{"crops": "ok", "ingredients": "ok", "labels": "ok", "resistance-sources": "ok", "resistances": "ok"},
+		},
+		{
+			"failure skips dependents",
+			nil,
+			[]string{"crops"},
+			map[string]string{"crops": "failed", "ingredients": "ok", "labels": "skipped (crops failed)", "resistance-sources": "ok", "resistances": "ok"},
+		},
+		{
+			"skips propagate",
+			nil,
+			[]string{"resistance-sources"},
+			map[string]string{
+				"crops":              "ok",
+				"ingredients":        "skipped (resistances failed)",
+				"labels":             "skipped (ingredients failed)",
+				"resistance-sources": "failed",
+				"resistances":        "skipped (resistance-sources failed)",
+			},
+		},
+		{
+			"first failed dependency named",
+			nil,
+			[]string{"crops", "resistances"},
+			map[string]string{"crops": "failed", "ingredients": "skipped (resistances failed)", "labels": "skipped (crops failed)", "resistance-sources": "ok", "resistances": "failed"},
+		},
+		{
+			"entities without files neither run nor block",
+			[]string{"resistance-sources", "resistances"},
+			nil,
+			map[string]string{"crops": "ok", "ingredients": "ok", "labels": "ok"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filenames := map[string]string{}
+			for _, entity := range entities {
+				filenames[entity.Entity] = entity.Entity + "-2023-10-17.json"
+			}
+			for _, entity := range test.missing {
+				delete(filenames, entity)
+			}
+			failing := map[string]bool{}
+			for _, entity := range test.failing {
+				failing[entity] = true
+			}
+
+			var ran []string
+			results, failed := runImports(order, filenames, func(entity importEntity, filename string) importResult {
+				if filename != filenames[entity.Entity] {
+					t.Errorf("%s imported from %s", entity.Entity, filename)
+				}
+				ran = append(ran, entity.Entity)
+				if failing[entity.Entity] {
+					return importResult{Status: "failed"}
+				}
+				return importResult{Status: "ok"}
+			})
+
+			got := map[string]string{}
+			var wantRan []string
+			for _, result := range results {
+				got[result.Entity] = result.Status
+				if result.Filename != filenames[result.Entity] {
+					t.Errorf("got file %s for %s", result.Filename, result.Entity)
+				}
+				if result.Status == "ok" || result.Status == "failed" {
+					wantRan = append(wantRan, result.Entity)
+				}
+				if failed[result.Entity] != (result.Status != "ok") {
+					t.Errorf("%s with status %q is in the failed set: %t", result.Entity, result.Status, failed[result.Entity])
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got statuses %v, want %v", got, test.want)
+			}
+
+			// Skipped entities are not imported, and the others are imported in order.
+			if !reflect.DeepEqual(ran, wantRan) {
+				t.Errorf("imported %q, want %q", ran, wantRan)
+			}
+		})
+	}
+}
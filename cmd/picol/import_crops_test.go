@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+func TestImportCropsWritesChunksAsTheyAreRead(t *testing.T) {
+	ctx, fake := newFakeDynamoDB(t)
+
+	// The importer reads and writes 1000 records at a time, so the invalid crop is only read once the first 1000 crops
+	// have been written.
+	crops := make([]picolApiV1.Crop, 1500)
+	for i := range crops {
+		crops[i] = picolApiV1.Crop{Id: i + 1, Name: fmt.Sprintf("CROP %d", i+1), Code: fmt.Sprintf("%04d", i+1)}
+	}
+	crops[1200].Code = "TOO LONG"
+
+	dir := t.TempDir()
+	filename := writeResponseFile(t, dir, "crops.json", crops...)
+
+	rc := importCrops(ctx, []string{"-strict", "-state-dir", dir, filename})
+	if rc == 0 {
+		t.Fatalf("import-crops accepted an invalid crop with -strict")
+	}
+	if keys := fake.Keys("TCrops"); len(keys) != 1000 {
+		t.Errorf("got %d crops, want the 1000 of the first chunk", len(keys))
+	}
+}
+
+func TestImportCropsChecksSizeBeforePruning(t *testing.T) {
+	ctx, fake := newFakeDynamoDB(t)
+	for id := 1; id <= 10; id++ {
+		fake.SetItem("TCrops", fmt.Sprintf(`{"Id": {"N": "%d"}, "Name": {"S": "CROP %d"}, "Code": {"S": "%04d"}}`, id, id, id))
+	}
+
+	dir := t.TempDir()
+	filename := writeResponseFile(t, dir, "crops.json",
+		picolApiV1.Crop{Id: 1, Name: "CROP 1", Code: "0001"},
+		picolApiV1.Crop{Id: 11, Name: "CROP 11", Code: "0011"})
+
+	// Two crops are below half of the ten in the table, so the file may be truncated.
+	rc := importCrops(ctx, []string{"-allow-update", "-prune", "-prune-max-percent", "100", "-state-dir", dir, filename})
+	if rc == 0 {
+		t.Fatalf("import-crops pruned a table of 10 crops to 2")
+	}
+	if keys := fake.Keys("TCrops"); len(keys) != 11 {
+		t.Errorf("got crops %q, want the 10 crops and crop 11", keys)
+	}
+
+	rc = importCrops(ctx, []string{"-allow-update", "-prune", "-prune-max-percent", "100", "-min-percent", "10", "-state-dir", dir, filename})
+	if rc != 0 {
+		t.Fatalf("import-crops exited with %d", rc)
+	}
+	if keys := fake.Keys("TCrops"); len(keys) != 2 {
+		t.Errorf("got crops %q, want 1 and 11", keys)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+func importApplications(ctx context.Context, args []string) int {
+	return runImport(ctx, args, enumDescriptor("import-applications", "application", "applications", "Applications",
+		applicationEnumValues, applicationDataValue))
+}
+
+func importIntendedUsers(ctx context.Context, args []string) int {
+	return runImport(ctx, args, enumDescriptor("import-intended-users", "intended user", "intended users", "IntendedUsers",
+		intendedUserEnumValues, intendedUserDataValue))
+}
+
+func importSignalWords(ctx context.Context, args []string) int {
+	return runImport(ctx, args, enumDescriptor("import-signal-words", "signal word", "signal words", "SignalWords",
+		signalWordEnumValues, signalWordDataValue))
+}
+
+func importStates(ctx context.Context, args []string) int {
+	return runImport(ctx, args, enumDescriptor("import-states", "state", "states", "States",
+		stateEnumValues, stateDataValue))
+}
+
+// enumDescriptor describes the import of an enumeration dataset whose values are also defined as Go constants in
+// ddbmodel. A value that disagrees with the Go constants is never written, and a dataset that lacks one of them fails
+// the import before anything is pruned.
+func enumDescriptor[T interface{ Validate() error }](subcommand string, kind string, noun string, tableName string, goValues func() []enumValue, dataValue func(T) enumValue) importer.Descriptor[T] {
+	// The values read so far. Any value that is not defined in Go fails the check, so there are never many.
+	var seen []enumValue
+
+	return importer.Descriptor[T]{
+		Subcommand: subcommand,
+		Kind:       kind,
+		Noun:       noun,
+		TableName:  tableName,
+		Id:         func(record T) int { return dataValue(record).Id },
+		Validate:   T.Validate,
+		Item: func(record T) (map[string]ddbTypes.AttributeValue, error) {
+			return enumItem(dataValue(record)), nil
+		},
+		Optional: []string{"Code"},
+		Check: func(records []T) error {
+			values := enumDataValues(records, dataValue)
+			seen = append(seen, values...)
+			return enumDiffsError(noun, compareEnum(kind, enumValuesWithIds(goValues(), values), values))
+		},
+		CheckComplete: func() error {
+			return enumDiffsError(noun, compareEnum(kind, goValues(), seen))
+		},
+	}
+}
+
+// enumValuesWithIds returns the values whose ids are among those of others.
+func enumValuesWithIds(values []enumValue, others []enumValue) []enumValue {
+	ids := make(map[int]bool, len(others))
+	for _, other := range others {
+		ids[other.Id] = true
+	}
+
+	var result []enumValue
+	for _, value := range values {
+		if ids[value.Id] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// enumDiffsError returns the error for the differences between an enumeration dataset and its Go definitions, or nil
+// if there are none.
+func enumDiffsError(noun string, diffs []string) error {
+	if len(diffs) > 0 {
+		return fmt.Errorf("the %s do not match the Go definitions:\n  %s", noun, strings.Join(diffs, "\n  "))
+	}
+	return nil
+}
+
+// enumItem returns the DynamoDB item for an enumeration value.
+func enumItem(value enumValue) map[string]ddbTypes.AttributeValue {
+	item := map[string]ddbTypes.AttributeValue{
+		"Id":   ddbutil.N(int64(value.Id)),
+		"Name": ddbutil.S(value.Name),
+	}
+
+	if value.Code != "" {
+		item["Code"] = ddbutil.S(value.Code)
+	}
+
+	return item
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+func TestImportApplicationsChecksGoDefinitions(t *testing.T) {
+	var applications []picolApiV1.Application
+	for _, value := range applicationEnumValues() {
+		applications = append(applications, picolApiV1.Application{Id: value.Id, Code: value.Code, Name: value.Name})
+	}
+	mismatched := append([]picolApiV1.Application(nil), applications...)
+	mismatched[0].Name = "HELICOPTER"
+
+	tests := []struct {
+		name         string
+		applications []picolApiV1.Application
+		wantRC       int
+		wantWritten  int
+	}{
+		{"all values", applications, 0, len(applications)},
+		// A missing value is only known once the whole file has been read, but the values that were written agree with
+		// the Go definitions.
+		{"missing value", applications[1:], 1, len(applications) - 1},
+		{"mismatched value", mismatched, 1, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, fake := newFakeDynamoDB(t)
+
+			dir := t.TempDir()
+			filename := writeResponseFile(t, dir, "applications.json", test.applications...)
+
+			rc := importApplications(ctx, []string{"-state-dir", dir, filename})
+			if rc != test.wantRC {
+				t.Errorf("import-applications exited with %d, want %d", rc, test.wantRC)
+			}
+			if written := len(fake.Keys("TApplications")); written != test.wantWritten {
+				t.Errorf("got %d applications, want %d", written, test.wantWritten)
+			}
+		})
+	}
+}
@@ -2,169 +2,128 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"flag"
-	"fmt"
-	"io"
-	"os"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
 	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
 )
 
 func importIngredients(ctx context.Context, args []string) int {
-	flags := flag.NewFlagSet("import-ingredients", flag.ExitOnError)
-	allowUpdate := flags.Bool("allow-update", false, "Allow updating existing ingredients.")
-	idSequenceOnly := flags.Bool("id-sequence-only", false, "Only update the id sequence, do not import ingredients.")
-	help := flags.Bool("help", false, "Show help.")
-
-	flags.Usage = func() {
-		out := flags.Output()
-		fmt.Fprintf(out, "Import ingredient data from a JSON file.\n")
-		fmt.Fprintf(out, "Usage: %s import-ingredients [options] <filename>\n", os.Args[0])
-		fmt.Fprintf(out, "\n")
-		fmt.Fprintf(out, "Options:\n")
-		flags.PrintDefaults()
-	}
+	return runImport(ctx, args, importer.Descriptor[picolApiV1.Ingredient]{
+		Subcommand:        "import-ingredients",
+		Kind:              "ingredient",
+		Noun:              "ingredients",
+		TableName:         "Ingredients",
+		SequenceName:      "Ingredients.Id",
+		Id:                func(ingredient picolApiV1.Ingredient) int { return ingredient.Id },
+		Validate:          picolApiV1.Ingredient.Validate,
+		Normalize:         picolApiV1.Ingredient.Normalize,
+		SortName:          picolApiV1.Ingredient.SortName,
+		Item:              ingredientItem,
+		References:        []importer.Reference{{TableName: "Labels", Attribute: "IngredientIds"}},
+		Optional:          []string{"ResistanceId", "ResistanceIds", "Notes", "ManagementCode"},
+		Related:           ingredientResistanceUpdates,
+		RelatedAttributes: []string{"ResistanceId", "ResistanceIds"},
+	})
+}
 
-	flags.Parse(args)
+// ingredientItem returns the DynamoDB item for an ingredient. Ingredients with only the null resistance have no
+// ResistanceId or ResistanceIds. Writing whole items also drops any legacy ManagementCode attribute.
+func ingredientItem(apiIngredient picolApiV1.Ingredient) (map[string]ddbTypes.AttributeValue, error) {
+	ingredient := ddbmodel.IngredientFromApi(apiIngredient)
 
-	if *help {
-		flags.SetOutput(os.Stdout)
-		flags.Usage()
-		return 0
+	item := map[string]ddbTypes.AttributeValue{
+		"Id":   ddbutil.N(int64(ingredient.Id)),
+		"Name": ddbutil.S(ingredient.Name),
+		"Code": ddbutil.S(ingredient.Code),
 	}
 
-	args = flags.Args()
-	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "No filename specified.\n")
-		flags.Usage()
-		return 1
+	if ingredient.ResistanceId != nil {
+		item["ResistanceId"] = ddbutil.N(int64(*ingredient.ResistanceId))
 	}
 
-	if len(args) > 1 {
-		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", args[1])
-		flags.Usage()
-		return 1
-	}
-
-	filename := args[0]
-	var input io.Reader
-
-	if filename == "-" {
-		// Read from stdin
-		input = os.Stdin
-	} else {
-		// Read from file
-		fd, err := os.Open(filename)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening file: %s\n", err)
-			return 1
+	if len(ingredient.ResistanceIds) > 0 {
+		resistanceIds := make([]int64, len(ingredient.ResistanceIds))
+		for i, id := range ingredient.ResistanceIds {
+			resistanceIds[i] = int64(id)
 		}
-		defer fd.Close()
-		input = fd
-	}
-
-	decoder := json.NewDecoder(input)
-	var ingredients picolApiV1.Response[picolApiV1.Ingredient]
-	err := decoder.Decode(&ingredients)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error decoding JSON: %s\n", err)
-		return 1
+		item["ResistanceIds"] = ddbutil.NS(resistanceIds)
 	}
 
-	awsConfig := CtxGetAWSConfig(ctx)
-	tablePrefix := CtxGetDynamoDBTablePrefix(ctx)
-	ddbClient := dynamodb.NewFromConfig(awsConfig)
-	highestIngredientId := 0
-	ingredientsUII := dynamodb.UpdateItemInput{
-		TableName: aws.String(fmt.Sprintf("%sIngredients", tablePrefix)),
-		ExpressionAttributeNames: map[string]string{
-			"#ResistanceId":   "ResistanceId",
-			"#Name":           "Name",
-			"#Code":           "Code",
-			"#Notes":          "Notes",
-			"#ManagementCode": "ManagementCode",
-		},
+	if ingredient.Notes != "" {
+		item["Notes"] = ddbutil.S(ingredient.Notes)
 	}
 
-	resistancesUII := dynamodb.UpdateItemInput{
-		TableName: aws.String(fmt.Sprintf("%sResistances", tablePrefix)),
-		ExpressionAttributeNames: map[string]string{
-			"#Ingredients": "Ingredients",
-		},
-		UpdateExpression: aws.String("ADD #Ingredients :Ingredient"),
-	}
+	return item, nil
+}
 
-	setAll := aws.String("SET #ResistanceId = :ResistanceId, #Name = :Name, #Code = :Code, #Notes = :Notes REMOVE #ManagementCode")
-	setAllRemoveNotes := aws.String("SET #ResistanceId = :ResistanceId, #Name = :Name, #Code = :Code REMOVE #ManagementCode, #Notes")
+// ingredientResistanceUpdates adds the ingredient to the Ingredients set of each of its resistances. An ingredient
+// that left a resistance is also removed from that resistance's set.
+func ingredientResistanceUpdates(tablePrefix string, ingredient picolApiV1.Ingredient, current map[string]ddbTypes.AttributeValue) []*dynamodb.UpdateItemInput {
+	var updates []*dynamodb.UpdateItemInput
 
-	if !*allowUpdate {
-		ingredientsUII.ConditionExpression = aws.String("attribute_not_exists(Id)")
+	resistanceIds := map[int64]bool{}
+	for _, resistance := range ingredient.ResistanceGroups() {
+		resistanceIds[int64(resistance.Id)] = true
 	}
 
-	for _, apiIngredient := range ingredients.Data {
-		fmt.Printf("%#v\n", apiIngredient)
-
-		if apiIngredient.Id > highestIngredientId {
-			highestIngredientId = apiIngredient.Id
-		}
-
-		if *idSequenceOnly {
-			continue
-		}
-
-		ingredientsUII.Key = map[string]ddbTypes.AttributeValue{
-			"Id": ddbutil.N(int64(apiIngredient.Id)),
-		}
-
-		ingredientsUII.ExpressionAttributeValues = map[string]ddbTypes.AttributeValue{
-			":ResistanceId": ddbutil.N(int64(apiIngredient.Resistance.Id)),
-			":Name":         ddbutil.S(apiIngredient.Name),
-			":Code":         ddbutil.S(apiIngredient.Code),
-		}
-
-		if apiIngredient.Notes != "" {
-			ingredientsUII.ExpressionAttributeValues[":Notes"] = ddbutil.S(apiIngredient.Notes)
-			ingredientsUII.UpdateExpression = setAll
-		} else {
-			ingredientsUII.UpdateExpression = setAllRemoveNotes
-		}
-
-		_, err = ddbClient.UpdateItem(ctx, &ingredientsUII)
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing ingredient: %s\n", err)
-			return 1
+	for _, previous := range previousResistanceIds(current) {
+		if !resistanceIds[previous] {
+			updates = append(updates, resistanceIngredientsUpdate(tablePrefix, previous, "DELETE", ingredient.Id))
 		}
+	}
 
-		if apiIngredient.Resistance.Code != "" { // Don't update the null item
-			resistancesUII.Key = map[string]ddbTypes.AttributeValue{
-				"Id": ddbutil.N(int64(apiIngredient.Resistance.Id)),
-			}
+	for _, resistance := range ingredient.ResistanceGroups() {
+		updates = append(updates, resistanceIngredientsUpdate(tablePrefix, int64(resistance.Id), "ADD", ingredient.Id))
+	}
 
-			resistancesUII.ExpressionAttributeValues = map[string]ddbTypes.AttributeValue{
-				":Ingredient": ddbutil.NS1(int64(apiIngredient.Id)),
-			}
+	return updates
+}
 
-			_, err = ddbClient.UpdateItem(ctx, &resistancesUII)
+// previousResistanceIds returns the resistance ids of an ingredient item that is already in the table, from both
+// ResistanceId and ResistanceIds.
+func previousResistanceIds(current map[string]ddbTypes.AttributeValue) []int64 {
+	var values []string
+	if n, ok := current["ResistanceId"].(*ddbTypes.AttributeValueMemberN); ok {
+		values = append(values, n.Value)
+	}
+	if ns, ok := current["ResistanceIds"].(*ddbTypes.AttributeValueMemberNS); ok {
+		values = append(values, ns.Value...)
+	}
 
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing ingredient to resistances: %s\n", err)
-				return 1
-			}
+	var ids []int64
+	seen := map[int64]bool{}
+	for _, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seen[id] {
+			continue
 		}
+		seen[id] = true
+		ids = append(ids, id)
 	}
 
-	sequenceName := fmt.Sprintf("%sIngredients.Id", tablePrefix)
-	err = MaybeUpdateSequence(ctx, ddbClient, sequenceName, int64(highestIngredientId)+1)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error updating sequence: %s\n", err)
-		return 1
-	}
+	return ids
+}
 
-	return 0
+// resistanceIngredientsUpdate returns the update that adds an ingredient to, or deletes it from, the Ingredients set
+// of a resistance. action is "ADD" or "DELETE".
+func resistanceIngredientsUpdate(tablePrefix string, resistanceId int64, action string, ingredientId int) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(tablePrefix + "Resistances"),
+		Key: map[string]ddbTypes.AttributeValue{
+			"Id": ddbutil.N(resistanceId),
+		},
+		ExpressionAttributeNames: map[string]string{
+			"#Ingredients": "Ingredients",
+		},
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":Ingredient": ddbutil.NS1(int64(ingredientId)),
+		},
+		UpdateExpression: aws.String(action + " #Ingredients :Ingredient"),
+	}
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbtest"
+)
+
+var (
+	hracO = picolApiV1.Resistance{Id: 72, Source: "HRAC", Code: "O", MethodOfAction: "Synthetic auxins"}
+	irac6 = picolApiV1.Resistance{Id: 83, Source: "IRAC", Code: "6", MethodOfAction: "Chloride channel activators"}
+)
+
+// newIngredientsImport returns a fake DynamoDB holding HRAC O and IRAC 6 without ingredients, and a function that runs
+// import-ingredients against it with the given options and ingredients.
+func newIngredientsImport(t *testing.T) (*ddbtest.Fake, func(args []string, ingredients ...picolApiV1.Ingredient) int) {
+	ctx, fake := newFakeDynamoDB(t)
+	fake.SetItem("TResistances", `{"Id": {"N": "72"}, "Source": {"S": "HRAC"}, "Code": {"S": "O"}}`)
+	fake.SetItem("TResistances", `{"Id": {"N": "83"}, "Source": {"S": "IRAC"}, "Code": {"S": "6"}}`)
+
+	dir := t.TempDir()
+	importFile := func(args []string, ingredients ...picolApiV1.Ingredient) int {
+		data, err := json.Marshal(picolApiV1.Response[picolApiV1.Ingredient]{Data: ingredients})
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, "ingredients.json")
+		err = os.WriteFile(filename, data, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		args = append([]string{"-allow-update", "-min-percent", "0", "-state-dir", dir}, args...)
+		return importIngredients(ctx, append(args, filename))
+	}
+
+	return fake, importFile
+}
+
+func ingredient(id int, code string, resistance picolApiV1.Resistance) picolApiV1.Ingredient {
+	return picolApiV1.Ingredient{Id: id, Name: "INGREDIENT " + code, Code: code, Resistance: resistance}
+}
+
+// resistanceIngredients returns the Ingredients set of a resistance item.
+func resistanceIngredients(fake *ddbtest.Fake, resistanceId string) []string {
+	set, ok := fake.Item("TResistances", resistanceId)["Ingredients"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var ids []string
+	for _, id := range set["NS"].([]any) {
+		ids = append(ids, id.(string))
+	}
+	return ids
+}
+
+func checkResistanceIngredients(t *testing.T, fake *ddbtest.Fake, want map[string][]string) {
+	t.Helper()
+	for resistanceId, ids := range want {
+		if got := resistanceIngredients(fake, resistanceId); !reflect.DeepEqual(got, ids) {
+			t.Errorf("resistance %s has ingredients %q, want %q", resistanceId, got, ids)
+		}
+	}
+}
+
+func TestImportIngredientsMovesIngredientBetweenResistances(t *testing.T) {
+	fake, importFile := newIngredientsImport(t)
+	null := picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}
+
+	// Ingredient 651 stays in IRAC 6 throughout, while ingredient 156 moves.
+	steps := []struct {
+		name       string
+		resistance picolApiV1.Resistance
+		want       map[string][]string
+	}{
+		{"into HRAC O", hracO, map[string][]string{"72": {"156"}, "83": {"651"}}},
+		{"from HRAC O to IRAC 6", irac6, map[string][]string{"72": nil, "83": {"156", "651"}}},
+		{"from IRAC 6 to the null resistance", null, map[string][]string{"72": nil, "83": {"651"}}},
+		{"from the null resistance to HRAC O", hracO, map[string][]string{"72": {"156"}, "83": {"651"}}},
+	}
+
+	for _, step := range steps {
+		rc := importFile(nil, ingredient(156, "030001", step.resistance), ingredient(651, "129099", irac6))
+		if rc != 0 {
+			t.Fatalf("%s: import-ingredients exited with %d", step.name, rc)
+		}
+
+		t.Run(step.name, func(t *testing.T) {
+			checkResistanceIngredients(t, fake, step.want)
+
+			_, hasResistanceId := fake.Item("TIngredients", "156")["ResistanceId"]
+			if hasResistanceId != !step.resistance.IsNull() {
+				t.Errorf("ingredient 156 has ResistanceId: %t, want %t", hasResistanceId, !step.resistance.IsNull())
+			}
+		})
+
+		if _, found := fake.Item("TResistances", "1")["Ingredients"]; found {
+			t.Fatalf("%s: the null resistance was given ingredients", step.name)
+		}
+	}
+}
+
+func TestImportIngredientsWritesMoveInOneTransaction(t *testing.T) {
+	fake, importFile := newIngredientsImport(t)
+
+	rc := importFile(nil, ingredient(156, "030001", hracO))
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+
+	// If adding the ingredient to its new resistance fails, the ingredient keeps its old resistance, so that a later
+	// run still knows to remove it from the old resistance's set.
+	fake.FailWrites["TResistances/83"] = true
+	rc = importFile([]string{"-continue-on-error"}, ingredient(156, "030001", irac6))
+	if rc == 0 {
+		t.Fatalf("import-ingredients succeeded despite a failing resistance update")
+	}
+
+	resistanceId := fake.Item("TIngredients", "156")["ResistanceId"].(map[string]any)["N"]
+	if resistanceId != "72" {
+		t.Errorf("ingredient 156 has ResistanceId %v after a failed move, want 72", resistanceId)
+	}
+	checkResistanceIngredients(t, fake, map[string][]string{"72": {"156"}, "83": nil})
+
+	delete(fake.FailWrites, "TResistances/83")
+	rc = importFile([]string{"-resume"}, ingredient(156, "030001", irac6))
+	if rc != 0 {
+		t.Fatalf("resumed import-ingredients exited with %d", rc)
+	}
+	checkResistanceIngredients(t, fake, map[string][]string{"72": nil, "83": {"156"}})
+}
+
+func TestImportIngredientsRereadsIngredientMovedByAnotherWriter(t *testing.T) {
+	fake, importFile := newIngredientsImport(t)
+
+	rc := importFile(nil, ingredient(156, "030001", hracO))
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+
+	// After the import reads ingredient 156 in HRAC O, another writer moves it to IRAC 6. Removing it from HRAC O
+	// alone would leave it in IRAC 6.
+	fake.Before["TransactWriteItems"] = func() {
+		fake.Item("TIngredients", "156")["ResistanceId"] = map[string]any{"N": "83"}
+		delete(fake.Item("TResistances", "72"), "Ingredients")
+		fake.Item("TResistances", "83")["Ingredients"] = map[string]any{"NS": []any{"156"}}
+	}
+
+	rc = importFile(nil, ingredient(156, "030001", picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}))
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+	if len(fake.Before) != 0 {
+		t.Fatalf("the move was not written in a transaction")
+	}
+
+	checkResistanceIngredients(t, fake, map[string][]string{"72": nil, "83": nil})
+	if _, found := fake.Item("TIngredients", "156")["ResistanceId"]; found {
+		t.Errorf("ingredient 156 kept its ResistanceId")
+	}
+}
+
+func TestImportIngredientsBatchesIngredientsThatKeepTheirResistance(t *testing.T) {
+	fake, importFile := newIngredientsImport(t)
+
+	rc := importFile(nil, ingredient(156, "030001", hracO), ingredient(651, "129099", irac6))
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+
+	// Renaming an ingredient leaves its resistance alone, so it needs no transaction.
+	fake.Before["TransactWriteItems"] = func() {
+		t.Errorf("an ingredient that kept its resistance was written in a transaction")
+	}
+	renamed := ingredient(651, "129099", irac6)
+	renamed.Name = "RENAMED"
+	rc = importFile(nil, ingredient(156, "030001", hracO), renamed)
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+
+	checkResistanceIngredients(t, fake, map[string][]string{"72": {"156"}, "83": {"651"}})
+	if name := fake.Item("TIngredients", "651")["Name"].(map[string]any)["S"]; name != "RENAMED" {
+		t.Errorf("ingredient 651 is named %v, want RENAMED", name)
+	}
+}
+
+func TestImportIngredientsInSeveralGroups(t *testing.T) {
+	fake, importFile := newIngredientsImport(t)
+
+	// An ingredient stored before ResistanceIds existed, in HRAC O only.
+	fake.SetItem("TIngredients", `{"Id": {"N": "156"}, "Name": {"S": "INGREDIENT 030001"}, "Code": {"S": "030001"}, "ResistanceId": {"N": "72"}}`)
+	fake.SetItem("TResistances", `{"Id": {"N": "72"}, "Source": {"S": "HRAC"}, "Code": {"S": "O"}, "Ingredients": {"NS": ["156"]}}`)
+
+	both := ingredient(156, "030001", irac6)
+	both.Resistances = []picolApiV1.Resistance{irac6, hracO}
+
+	steps := []struct {
+		name       string
+		ingredient picolApiV1.Ingredient
+		want       map[string][]string
+		wantIds    []string
+	}{
+		{"into both groups", both, map[string][]string{"72": {"156"}, "83": {"156"}}, []string{"72", "83"}},
+		{"out of HRAC O", ingredient(156, "030001", irac6), map[string][]string{"72": nil, "83": {"156"}}, []string{"83"}},
+		{"back into both groups", both, map[string][]string{"72": {"156"}, "83": {"156"}}, []string{"72", "83"}},
+		{"out of both groups", ingredient(156, "030001", picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}), map[string][]string{"72": nil, "83": nil}, nil},
+	}
+
+	for _, step := range steps {
+		rc := importFile(nil, step.ingredient)
+		if rc != 0 {
+			t.Fatalf("%s: import-ingredients exited with %d", step.name, rc)
+		}
+
+		t.Run(step.name, func(t *testing.T) {
+			checkResistanceIngredients(t, fake, step.want)
+
+			var ids []string
+			if set, ok := fake.Item("TIngredients", "156")["ResistanceIds"].(map[string]any); ok {
+				for _, id := range set["NS"].([]any) {
+					ids = append(ids, id.(string))
+				}
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, step.wantIds) {
+				t.Errorf("ingredient 156 has ResistanceIds %v, want %v", ids, step.wantIds)
+			}
+		})
+	}
+
+	// The primary group is ResistanceId, so readers that only know one group see IRAC 6.
+	rc := importFile(nil, both)
+	if rc != 0 {
+		t.Fatalf("import-ingredients exited with %d", rc)
+	}
+	if resistanceId := fake.Item("TIngredients", "156")["ResistanceId"]; !reflect.DeepEqual(resistanceId, map[string]any{"N": "83"}) {
+		t.Errorf("got ResistanceId %v, want 83", resistanceId)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+// importLabels imports labels. State records are nested in the label item rather than stored in a table of their
+// own, so a label and its state records are always written together, with or without -atomic.
+func importLabels(ctx context.Context, args []string) int {
+	return runImport(ctx, args, importer.Descriptor[picolApiV1.Label]{
+		Subcommand:   "import-labels",
+		Kind:         "label",
+		Noun:         "labels",
+		TableName:    "Labels",
+		SequenceName: "Labels.Id",
+		Id:           func(label picolApiV1.Label) int { return label.Id },
+		Validate:     picolApiV1.Label.Validate,
+		Normalize:    picolApiV1.Label.Normalize,
+		SortName:     picolApiV1.Label.SortName,
+		Item: func(apiLabel picolApiV1.Label) (map[string]ddbTypes.AttributeValue, error) {
+			label, err := labelFromApi(apiLabel)
+			if err != nil {
+				return nil, err
+			}
+			return labelItem(label), nil
+		},
+		Optional: []string{
+			"IngredientIds", "PesticideTypeIds", "CropIds", "PestIds", "Sln", "SlnName", "SlnExpiration", "StateRecords", "Supplemental",
+			"SupplementalName", "SupplementalExpiration", "Formulation", "SignalWordId", "Usage", "Organic", "EsaNotice",
+			"Section18",
+		},
+	})
+}
+
+// labelFromApi converts a version 1 API label into its DynamoDB representation, replacing embedded objects with
+// references by id.
+func labelFromApi(apiLabel picolApiV1.Label) (ddbmodel.Label, error) {
+	label := ddbmodel.Label{
+		Id:               apiLabel.Id,
+		Name:             apiLabel.Name,
+		EpaNumber:        apiLabel.EpaNumber,
+		IntendedUserId:   apiLabel.IntendedUser.Id,
+		RegistrantId:     apiLabel.Registrant.Id,
+		Sln:              apiLabel.Sln,
+		SlnName:          apiLabel.SlnName,
+		Supplemental:     apiLabel.Supplemental,
+		SupplementalName: apiLabel.SupplementalName,
+		Formulation:      apiLabel.Formulation,
+		Usage:            apiLabel.Usage,
+		Organic:          apiLabel.Organic,
+		EsaNotice:        apiLabel.EsaNotice,
+		Section18:        apiLabel.Section18,
+	}
+
+	for _, ingredient := range apiLabel.Ingredients {
+		label.IngredientIds = append(label.IngredientIds, ingredient.Id)
+	}
+
+	for _, pesticideType := range apiLabel.PesticideTypes {
+		label.PesticideTypeIds = append(label.PesticideTypeIds, pesticideType.Id)
+	}
+
+	for _, crop := range apiLabel.Crops {
+		label.CropIds = append(label.CropIds, crop.Id)
+	}
+
+	for _, pest := range apiLabel.Pests {
+		label.PestIds = append(label.PestIds, pest.Id)
+	}
+
+	if apiLabel.SlnExpiration != nil {
+		label.SlnExpiration = apiLabel.SlnExpiration.ISODate()
+	}
+
+	if apiLabel.SupplementalExpiration != nil {
+		label.SupplementalExpiration = apiLabel.SupplementalExpiration.ISODate()
+	}
+
+	for _, apiStateRecord := range apiLabel.StateRecords {
+		label.StateRecords = append(label.StateRecords, ddbmodel.LabelStateRecord{
+			Id:       apiStateRecord.Id,
+			StateId:  apiStateRecord.StateId,
+			AgencyId: apiStateRecord.AgencyId,
+			Version:  apiStateRecord.Version,
+			Year:     apiStateRecord.Year,
+			I502:     apiStateRecord.I502,
+			Essb6206: apiStateRecord.Essb6206,
+		})
+	}
+
+	if apiLabel.SignalWord != "" {
+		signalWord, err := ddbmodel.ParseSignalWordCode(apiLabel.SignalWord)
+		if err != nil {
+			signalWord, err = ddbmodel.ParseSignalWordName(apiLabel.SignalWord)
+		}
+		if err != nil {
+			return ddbmodel.Label{}, err
+		}
+		signalWordId := int(signalWord)
+		label.SignalWordId = &signalWordId
+	}
+
+	return label, nil
+}
+
+// labelItem returns the DynamoDB item for a label. Empty optional attributes are omitted.
+func labelItem(label ddbmodel.Label) map[string]ddbTypes.AttributeValue {
+	item := map[string]ddbTypes.AttributeValue{}
+	setOptional := func(name string, value ddbTypes.AttributeValue) {
+		if value != nil {
+			item[name] = value
+		}
+	}
+
+	item["Id"] = ddbutil.N(int64(label.Id))
+	item["Name"] = ddbutil.S(label.Name)
+	item["EpaNumber"] = ddbutil.S(label.EpaNumber)
+	item["IntendedUserId"] = ddbutil.N(int64(label.IntendedUserId))
+	item["RegistrantId"] = ddbutil.N(int64(label.RegistrantId))
+	setOptional("IngredientIds", optionalNS(label.IngredientIds))
+	setOptional("PesticideTypeIds", optionalNS(label.PesticideTypeIds))
+	setOptional("CropIds", optionalNS(label.CropIds))
+	setOptional("PestIds", optionalNS(label.PestIds))
+	setOptional("Sln", optionalS(label.Sln))
+	setOptional("SlnName", optionalS(label.SlnName))
+	setOptional("SlnExpiration", optionalS(label.SlnExpiration))
+	setOptional("StateRecords", labelStateRecordsAV(label.StateRecords))
+	setOptional("Supplemental", optionalS(label.Supplemental))
+	setOptional("SupplementalName", optionalS(label.SupplementalName))
+	setOptional("SupplementalExpiration", optionalS(label.SupplementalExpiration))
+	setOptional("Formulation", optionalS(label.Formulation))
+	setOptional("SignalWordId", optionalN(label.SignalWordId))
+	setOptional("Usage", optionalS(label.Usage))
+	setOptional("Organic", optionalBOOL(label.Organic))
+	setOptional("EsaNotice", optionalBOOL(label.EsaNotice))
+	setOptional("Section18", optionalS(label.Section18))
+
+	return item
+}
+
+// labelStateRecordsAV returns the state records as a list of maps, or nil if there are none.
+func labelStateRecordsAV(stateRecords []ddbmodel.LabelStateRecord) ddbTypes.AttributeValue {
+	if len(stateRecords) == 0 {
+		return nil
+	}
+
+	l := make([]ddbTypes.AttributeValue, 0, len(stateRecords))
+	for _, stateRecord := range stateRecords {
+		m := map[string]ddbTypes.AttributeValue{
+			"Id":       ddbutil.N(int64(stateRecord.Id)),
+			"StateId":  ddbutil.N(int64(stateRecord.StateId)),
+			"Year":     ddbutil.N(int64(stateRecord.Year)),
+			"I502":     ddbutil.BOOL(stateRecord.I502),
+			"Essb6206": ddbutil.BOOL(stateRecord.Essb6206),
+		}
+		if stateRecord.AgencyId != "" {
+			m["AgencyId"] = ddbutil.S(stateRecord.AgencyId)
+		}
+		if stateRecord.Version != "" {
+			m["Version"] = ddbutil.S(stateRecord.Version)
+		}
+		l = append(l, ddbutil.M(m))
+	}
+
+	return ddbutil.L(l)
+}
+
+// optionalS returns a string attribute value, or nil if s is empty.
+func optionalS(s string) ddbTypes.AttributeValue {
+	if s == "" {
+		return nil
+	}
+	return ddbutil.S(s)
+}
+
+// optionalN returns a number attribute value, or nil if i is nil.
+func optionalN(i *int) ddbTypes.AttributeValue {
+	if i == nil {
+		return nil
+	}
+	return ddbutil.N(int64(*i))
+}
+
+// optionalNS returns a number set attribute value, or nil if ns is empty since DynamoDB does not allow empty sets.
+func optionalNS(ns []int) ddbTypes.AttributeValue {
+	if len(ns) == 0 {
+		return nil
+	}
+	ns64 := make([]int64, len(ns))
+	for i, n := range ns {
+		ns64[i] = int64(n)
+	}
+	return ddbutil.NS(ns64)
+}
+
+// optionalBOOL returns a boolean attribute value, or nil if b is nil.
+func optionalBOOL(b *bool) ddbTypes.AttributeValue {
+	if b == nil {
+		return nil
+	}
+	return ddbutil.BOOL(*b)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+func importPesticideTypes(ctx context.Context, args []string) int {
+	return runImport(ctx, args, importer.Descriptor[picolApiV1.PesticideType]{
+		Subcommand:   "import-pesticide-types",
+		Kind:         "pesticide type",
+		Noun:         "pesticide types",
+		TableName:    "PesticideTypes",
+		SequenceName: "PesticideTypes.Id",
+		Id:           func(pesticideType picolApiV1.PesticideType) int { return pesticideType.Id },
+		Validate:     picolApiV1.PesticideType.Validate,
+		Normalize:    picolApiV1.PesticideType.Normalize,
+		SortName:     picolApiV1.PesticideType.SortName,
+		Item:         pesticideTypeItem,
+		References:   []importer.Reference{{TableName: "Labels", Attribute: "PesticideTypeIds"}},
+	})
+}
+
+// pesticideTypeItem returns the DynamoDB item for a pesticide type.
+func pesticideTypeItem(pesticideType picolApiV1.PesticideType) (map[string]ddbTypes.AttributeValue, error) {
+	return map[string]ddbTypes.AttributeValue{
+		"Id":   ddbutil.N(int64(pesticideType.Id)),
+		"Code": ddbutil.S(pesticideType.Code),
+		"Name": ddbutil.S(pesticideType.Name),
+	}, nil
+}
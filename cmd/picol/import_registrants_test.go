@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+// readReport reads the JSON report of an import.
+func readReport(t *testing.T, filename string) importer.Report {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report importer.Report
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestImportRegistrantsNormalizesWebsites(t *testing.T) {
+	ctx, fake := newFakeDynamoDB(t)
+
+	dir := t.TempDir()
+	filename := writeResponseFile(t, dir, "registrants.json",
+		picolApiV1.Registrant{Id: 1, Name: "1ST BIO PRODUCTS", Website: "www.1stbioproducts.com"},
+		picolApiV1.Registrant{Id: 2, Name: "3 MOMS ORGANICS", Website: "WWW.3MOMSORGANICS.COM"},
+		picolApiV1.Registrant{Id: 3, Name: "ACME", Website: "https://www.acme.com"},
+		picolApiV1.Registrant{Id: 4, Name: "NO WEBSITE"},
+		picolApiV1.Registrant{Id: 5, Name: "MALFORMED", Website: "ftp://acme"})
+	reportFile := filepath.Join(dir, "report.json")
+
+	rc := importRegistrants(ctx, []string{"-state-dir", dir, "-report", reportFile, filename})
+	if rc != 0 {
+		t.Fatalf("import-registrants exited with %d", rc)
+	}
+
+	tests := []struct {
+		id          string
+		url         any
+		originalUrl any
+	}{
+		{"1", map[string]any{"S": "https://www.1stbioproducts.com"}, map[string]any{"S": "www.1stbioproducts.com"}},
+		{"2", map[string]any{"S": "https://www.3momsorganics.com"}, map[string]any{"S": "WWW.3MOMSORGANICS.COM"}},
+		{"3", map[string]any{"S": "https://www.acme.com"}, nil},
+		{"4", nil, nil},
+		{"5", nil, map[string]any{"S": "ftp://acme"}},
+	}
+	for _, test := range tests {
+		item := fake.Item("TRegistrants", test.id)
+		if url := item["Url"]; !reflect.DeepEqual(url, test.url) {
+			t.Errorf("got Url %v for registrant %s, want %v", url, test.id, test.url)
+		}
+		if originalUrl := item["OriginalUrl"]; !reflect.DeepEqual(originalUrl, test.originalUrl) {
+			t.Errorf("got OriginalUrl %v for registrant %s, want %v", originalUrl, test.id, test.originalUrl)
+		}
+	}
+
+	// The malformed website is reported, and fails the registrant with -strict.
+	report := readReport(t, reportFile)
+	want := []importer.RecordError{{Key: "Id=5", Error: `Website "ftp://acme" is not a host name or an http or https URL`}}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("got warnings %v, want %v", report.Warnings, want)
+	}
+
+	rc = importRegistrants(ctx, []string{"-strict", "-allow-update", "-state-dir", dir, filename})
+	if rc == 0 {
+		t.Errorf("import-registrants accepted a malformed website with -strict")
+	}
+}
+
+func TestImportRegistrantsMarksPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+
+		// The registrants written, with their Reserved attribute.
+		want map[string]any
+	}{
+		{"reserved", nil, map[string]any{"1": nil, "840": map[string]any{"BOOL": true}, "1157": map[string]any{"BOOL": true}}},
+		{"-skip-reserved", []string{"-skip-reserved"}, map[string]any{"1": nil}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, fake := newFakeDynamoDB(t)
+
+			dir := t.TempDir()
+			filename := writeResponseFile(t, dir, "registrants.json",
+				picolApiV1.Registrant{Id: 1, Name: "CRODA INC -RESERVED-"},
+				picolApiV1.Registrant{Id: 840, Name: "-RESERVED-"},
+				picolApiV1.Registrant{Id: 1157, Name: "-RESERVED-"})
+
+			rc := importRegistrants(ctx, append([]string{"-state-dir", dir}, append(test.args, filename)...))
+			if rc != 0 {
+				t.Fatalf("import-registrants exited with %d", rc)
+			}
+
+			got := map[string]any{}
+			for _, key := range fake.Keys("TRegistrants") {
+				got[key] = fake.Item("TRegistrants", key)["Reserved"]
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got registrants %v, want %v", got, test.want)
+			}
+
+			// Skipped placeholders still reserve their ids.
+			want := map[string]any{"N": "1158"}
+			if nextId := fake.Item("TSequences", "TRegistrants.Id")["NextId"]; !reflect.DeepEqual(nextId, want) {
+				t.Errorf("got next id %v, want %v", nextId, want)
+			}
+		})
+	}
+}
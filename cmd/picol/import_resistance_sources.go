@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
+)
+
+func importResistanceSources(ctx context.Context, args []string) int {
+	return runImport(ctx, args, importer.Descriptor[picolApiV1.ResistanceSource]{
+		Subcommand:    "import-resistance-sources",
+		Kind:          "resistance source",
+		Noun:          "resistance sources",
+		TableName:     "ResistanceSources",
+		KeyAttributes: []string{"Source"},
+		Key:           func(source picolApiV1.ResistanceSource) string { return "Source=" + source.Source },
+		Validate:      picolApiV1.ResistanceSource.Validate,
+		Normalize:     picolApiV1.ResistanceSource.Normalize,
+		Item:          resistanceSourceItem,
+		References:    []importer.Reference{{TableName: "Resistances", Attribute: "Source"}},
+		Optional:      []string{"Url", "Version", "PublishedAt", "Codes"},
+	})
+}
+
+// resistanceSourceItem returns the DynamoDB item for a resistance source, with its website as a canonical URL.
+func resistanceSourceItem(apiSource picolApiV1.ResistanceSource) (map[string]ddbTypes.AttributeValue, error) {
+	source, err := ddbmodel.ResistanceSourceFromApi(apiSource)
+	if err != nil {
+		return nil, err
+	}
+
+	item := map[string]ddbTypes.AttributeValue{
+		"Source": ddbutil.S(source.Source),
+		"Name":   ddbutil.S(source.Name),
+	}
+
+	if source.Url != "" {
+		item["Url"] = ddbutil.S(source.Url)
+	}
+
+	if source.Version != "" {
+		item["Version"] = ddbutil.S(source.Version)
+	}
+
+	if source.PublishedAt != "" {
+		item["PublishedAt"] = ddbutil.S(source.PublishedAt)
+	}
+
+	if len(source.Codes) > 0 {
+		item["Codes"] = ddbutil.SS(source.Codes)
+	}
+
+	return item, nil
+}
@@ -2,140 +2,188 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
 	"github.com/corbaltcode/picol/internal/ddbutil"
+	"github.com/corbaltcode/picol/internal/importer"
 )
 
 func importResistances(ctx context.Context, args []string) int {
-	flags := flag.NewFlagSet("import-resistances", flag.ExitOnError)
-	allowUpdate := flags.Bool("allow-update", false, "Allow updating existing resistances.")
-	idSequenceOnly := flags.Bool("id-sequence-only", false, "Only update the id sequence, do not import resistances.")
-	help := flags.Bool("help", false, "Show help.")
-	clearIngredients := flags.Bool("clear-ingredients", true, "Clear the ingredients list for each imported resistance.")
-
-	flags.Usage = func() {
-		out := flags.Output()
-		fmt.Fprintf(out, "Import resistance data from a JSON file.\n")
-		fmt.Fprintf(out, "Usage: %s import-resistances [options] <filename>\n", os.Args[0])
-		fmt.Fprintf(out, "\n")
-		fmt.Fprintf(out, "Options:\n")
-		flags.PrintDefaults()
-	}
-
-	flags.Parse(args)
-
-	if *help {
-		flags.SetOutput(os.Stdout)
-		flags.Usage()
-		return 0
-	}
+	var clearIngredients bool
+	var sourcesFile string
+	var skipSourceCheck bool
+
+	// The sources the resistances are checked against, read with the first chunk of resistances.
+	var fileSources, storedSources map[string]picolApiV1.ResistanceSource
+
+	return runImport(ctx, args, importer.Descriptor[picolApiV1.Resistance]{
+		Subcommand:   "import-resistances",
+		Kind:         "resistance",
+		Noun:         "resistances",
+		TableName:    "Resistances",
+		SequenceName: "Resistances.Id",
+		Id:           func(resistance picolApiV1.Resistance) int { return resistance.Id },
+		Validate:     picolApiV1.Resistance.Validate,
+		Normalize:    picolApiV1.Resistance.Normalize,
+		Item:         resistanceItem,
+		References: []importer.Reference{
+			{TableName: "Ingredients", Attribute: "ResistanceId"},
+			{TableName: "Ingredients", Attribute: "ResistanceIds"},
+		},
+		Preserve: func() []string {
+			// The ingredients list is maintained by import-ingredients.
+			if clearIngredients {
+				return nil
+			}
+			return []string{"Ingredients"}
+		},
+		Check: func(resistances []picolApiV1.Resistance) error {
+			if skipSourceCheck && sourcesFile != "" {
+				return errors.New("-sources cannot be used with -skip-source-check")
+			}
+			if sourcesFile == "" {
+				return nil
+			}
+			if fileSources == nil {
+				sources, err := readResistanceSourcesFile(sourcesFile)
+				if err != nil {
+					return err
+				}
+				fileSources = sources
+			}
+			return checkResistanceSources(resistances, fileSources, sourcesFile)
+		},
+		CheckStored: func(ctx context.Context, config importer.Config, resistances []picolApiV1.Resistance) error {
+			if sourcesFile != "" || skipSourceCheck {
+				return nil
+			}
+			if storedSources == nil {
+				sources, err := storedResistanceSources(ctx, config)
+				if err != nil {
+					return err
+				}
+				storedSources = sources
+			}
+			if len(storedSources) == 0 {
+				return fmt.Errorf("no resistance sources are stored in %sResistanceSources to check the codes of the resistances against; run import-resistance-sources first, give -sources, or use -skip-source-check", config.TablePrefix)
+			}
+			return checkResistanceSources(resistances, storedSources, config.TablePrefix+"ResistanceSources")
+		},
+		Flags: func(flags *flag.FlagSet) {
+			flags.BoolVar(&clearIngredients, "clear-ingredients", true, "Clear the ingredients list for each imported resistance.")
+			flags.StringVar(&sourcesFile, "sources", "", "Check the source and code of each resistance against the classifications in this resistance source file instead of those stored in the ResistanceSources table.")
+			flags.BoolVar(&skipSourceCheck, "skip-source-check", false, "Import the resistances without checking their sources and codes against a classification. Without it, an empty ResistanceSources table is an error.")
+		},
+	})
+}
 
-	args = flags.Args()
-	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "No filename specified.\n")
-		flags.Usage()
-		return 1
+// resistanceItem returns the DynamoDB item for a resistance. The null resistance is not stored, so it is skipped. Any
+// other resistance without a source, code or method of action is rejected, even without -strict.
+func resistanceItem(apiResistance picolApiV1.Resistance) (map[string]ddbTypes.AttributeValue, error) {
+	resistance, ok := ddbmodel.ResistanceFromApi(apiResistance)
+	if !ok {
+		return nil, nil
 	}
-
-	if len(args) > 1 {
-		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", args[1])
-		flags.Usage()
-		return 1
+	if resistance.Source == "" && resistance.Code == "" && resistance.MethodOfAction == "" {
+		return nil, fmt.Errorf("resistance %d has no source, code or method of action, but only the null resistance (id %d) may be empty", resistance.Id, picolApiV1.NullResistanceId)
 	}
 
-	filename := args[0]
-	var input io.Reader
+	return map[string]ddbTypes.AttributeValue{
+		"Id":             ddbutil.N(int64(resistance.Id)),
+		"Source":         ddbutil.S(resistance.Source),
+		"Code":           ddbutil.S(resistance.Code),
+		"MethodOfAction": ddbutil.S(resistance.MethodOfAction),
+	}, nil
+}
 
-	if filename == "-" {
-		// Read from stdin
-		input = os.Stdin
-	} else {
-		// Read from file
-		fd, err := os.Open(filename)
+// readResistanceSourcesFile reads the resistance sources in a resistance source file, by source.
+func readResistanceSourcesFile(filename string) (map[string]picolApiV1.ResistanceSource, error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	sourcesByName := map[string]picolApiV1.ResistanceSource{}
+	decoder := picolApiV1.NewResponseDecoder[picolApiV1.ResistanceSource](fd)
+	for {
+		source, err := decoder.Next()
+		if err == io.EOF {
+			return sourcesByName, nil
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening file: %s\n", err)
-			return 1
+			return nil, fmt.Errorf("error decoding %s: %w", filename, err)
 		}
-		defer fd.Close()
-		input = fd
-	}
-
-	decoder := json.NewDecoder(input)
-	var resistances picolApiV1.Response[picolApiV1.Resistance]
-	err := decoder.Decode(&resistances)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error decoding JSON: %s\n", err)
-		return 1
+		sourcesByName[source.Source] = source
 	}
+}
 
-	awsConfig := CtxGetAWSConfig(ctx)
-	tablePrefix := CtxGetDynamoDBTablePrefix(ctx)
-	ddbClient := dynamodb.NewFromConfig(awsConfig)
-	highestResistanceId := 0
-	uii := dynamodb.UpdateItemInput{
-		TableName: aws.String(fmt.Sprintf("%sResistances", tablePrefix)),
-		ExpressionAttributeNames: map[string]string{
-			"#Source":         "Source",
-			"#Code":           "Code",
-			"#MethodOfAction": "MethodOfAction",
-		},
-	}
+// storedResistanceSources reads the resistance sources stored in the ResistanceSources table, by source. Retired
+// sources are left out. A missing table has no sources.
+func storedResistanceSources(ctx context.Context, config importer.Config) (map[string]picolApiV1.ResistanceSource, error) {
+	client := dynamodb.NewFromConfig(config.AWSConfig)
+	tableName := config.TablePrefix + "ResistanceSources"
 
-	if *clearIngredients {
-		uii.UpdateExpression = aws.String("SET #Source = :Source, #Code = :Code, #MethodOfAction = :MethodOfAction REMOVE #Ingredients")
-		uii.ExpressionAttributeNames["#Ingredients"] = "Ingredients"
-	} else {
-		uii.UpdateExpression = aws.String("SET #Source = :Source, #Code = :Code, #MethodOfAction = :MethodOfAction")
+	items, err := ddbutil.ScanAttributes(ctx, client, tableName, []string{"Source", "Name", "Version", "Codes", "Retired"})
+	var rnfe *ddbTypes.ResourceNotFoundException
+	if errors.As(err, &rnfe) {
+		return nil, nil
 	}
-
-	if !*allowUpdate {
-		uii.ConditionExpression = aws.String("attribute_not_exists(Id)")
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", tableName, err)
 	}
 
-	for _, apiResistance := range resistances.Data {
-		fmt.Printf("%#v\n", apiResistance)
-
-		if apiResistance.Id > highestResistanceId {
-			highestResistanceId = apiResistance.Id
-		}
-
-		if *idSequenceOnly {
+	sourcesByName := make(map[string]picolApiV1.ResistanceSource, len(items))
+	for _, item := range items {
+		if ddbutil.AsBOOL(item["Retired"]) {
 			continue
 		}
-
-		uii.Key = map[string]ddbTypes.AttributeValue{
-			"Id": ddbutil.N(int64(apiResistance.Id)),
+		source := ddbmodel.ResistanceSource{
+			Source:  ddbutil.AsS(item["Source"]),
+			Name:    ddbutil.AsS(item["Name"]),
+			Version: ddbutil.AsS(item["Version"]),
+			Codes:   ddbutil.AsSS(item["Codes"]),
 		}
+		sourcesByName[source.Source] = ddbmodel.ResistanceSourceToApi(source)
+	}
+	return sourcesByName, nil
+}
 
-		uii.ExpressionAttributeValues = map[string]ddbTypes.AttributeValue{
-			":Source":         ddbutil.S(apiResistance.Source),
-			":Code":           ddbutil.S(apiResistance.Code),
-			":MethodOfAction": ddbutil.S(apiResistance.MethodOfAction),
+// checkResistanceSources checks that every resistance, other than the null resistance, has a source in sources and a
+// code in that source's classification. Sources that list no codes only have their name checked. where names the
+// file or table the sources came from.
+func checkResistanceSources(resistances []picolApiV1.Resistance, sources map[string]picolApiV1.ResistanceSource, where string) error {
+	var problems []string
+	for _, resistance := range resistances {
+		if resistance.IsNull() {
+			continue
 		}
 
-		_, err = ddbClient.UpdateItem(ctx, &uii)
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing resistance: %s\n", err)
-			return 1
+		source, found := sources[resistance.Source]
+		switch {
+		case !found:
+			problems = append(problems, fmt.Sprintf("resistance %d: source %q is not in %s", resistance.Id, resistance.Source, where))
+		case len(source.Codes) > 0 && !source.HasCode(resistance.Code):
+			edition := source.Source
+			if source.Version != "" {
+				edition += " " + source.Version
+			}
+			problems = append(problems, fmt.Sprintf("resistance %d: code %q is not in the %s classification", resistance.Id, resistance.Code, edition))
 		}
 	}
 
-	sequenceName := fmt.Sprintf("%sResistances.Id", tablePrefix)
-	err = MaybeUpdateSequence(ctx, ddbClient, sequenceName, int64(highestResistanceId)+1)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error updating sequence: %s\n", err)
-		return 1
+	if len(problems) > 0 {
+		return fmt.Errorf("the resistances do not match the resistance sources:\n  %s", strings.Join(problems, "\n  "))
 	}
 
-	return 0
+	return nil
 }
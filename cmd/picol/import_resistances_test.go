@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+// writeResponseFile writes records as a version 1 API response and returns the file name.
+func writeResponseFile[T any](t *testing.T, dir string, name string, records ...T) string {
+	t.Helper()
+
+	data, err := json.Marshal(picolApiV1.Response[T]{Data: records})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, name)
+	err = os.WriteFile(filename, data, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestImportResistancesChecksSources(t *testing.T) {
+	irac := `{"Source": {"S": "IRAC"}, "Name": {"S": "Insecticide Resistance Action Committee"}, "Codes": {"SS": ["1", "6"]}}`
+	retiredIrac := `{"Source": {"S": "IRAC"}, "Name": {"S": "Insecticide Resistance Action Committee"}, "Codes": {"SS": ["1", "6"]}, "Retired": {"BOOL": true}}`
+	null := picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}
+	irac99 := picolApiV1.Resistance{Id: 84, Source: "IRAC", Code: "99", MethodOfAction: "Unknown"}
+
+	tests := []struct {
+		name        string
+		sources     []string
+		args        []string
+		resistances []picolApiV1.Resistance
+		wantRC      int
+	}{
+		{"listed code", []string{irac}, nil, []picolApiV1.Resistance{null, irac6}, 0},
+		{"unlisted code", []string{irac}, nil, []picolApiV1.Resistance{irac6, irac99}, 1},
+		{"unknown source", []string{irac}, nil, []picolApiV1.Resistance{hracO}, 1},
+		{"retired source", []string{retiredIrac}, nil, []picolApiV1.Resistance{irac6}, 1},
+		{"no sources", nil, nil, []picolApiV1.Resistance{irac6}, 1},
+		{"no sources with -skip-source-check", nil, []string{"-skip-source-check"}, []picolApiV1.Resistance{irac99}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, fake := newFakeDynamoDB(t)
+			for _, source := range test.sources {
+				fake.SetItem("TResistanceSources", source)
+			}
+
+			dir := t.TempDir()
+			filename := writeResponseFile(t, dir, "resistances.json", test.resistances...)
+			args := append([]string{"-min-percent", "0", "-state-dir", dir}, test.args...)
+
+			rc := importResistances(ctx, append(args, filename))
+			if rc != test.wantRC {
+				t.Fatalf("import-resistances exited with %d, want %d", rc, test.wantRC)
+			}
+
+			// A failed check stops the import before anything is written.
+			if written := len(fake.Keys("TResistances")) > 0; written != (rc == 0) {
+				t.Errorf("resistances written: %t, want %t", written, rc == 0)
+			}
+		})
+	}
+}
+
+func TestImportResistancesChecksSourcesFile(t *testing.T) {
+	ctx, fake := newFakeDynamoDB(t)
+
+	dir := t.TempDir()
+	sourcesFile := writeResponseFile(t, dir, "resistance-sources.json",
+		picolApiV1.ResistanceSource{Source: "HRAC", Name: "Herbicide Resistance Action Committee", Codes: []string{"A", "O"}},
+		picolApiV1.ResistanceSource{Source: "IRAC", Name: "Insecticide Resistance Action Committee", Codes: []string{"6"}})
+	filename := writeResponseFile(t, dir, "resistances.json", hracO, irac6)
+
+	// The stored sources are empty, but the file is checked instead.
+	rc := importResistances(ctx, []string{"-min-percent", "0", "-state-dir", dir, "-sources", sourcesFile, filename})
+	if rc != 0 {
+		t.Fatalf("import-resistances exited with %d", rc)
+	}
+	if keys := fake.Keys("TResistances"); len(keys) != 2 {
+		t.Errorf("got resistances %q, want 72 and 83", keys)
+	}
+
+	rc = importResistances(ctx, []string{"-min-percent", "0", "-state-dir", dir, "-sources", sourcesFile, "-skip-source-check", filename})
+	if rc == 0 {
+		t.Errorf("import-resistances accepted -sources with -skip-source-check")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"sort"
+	"syscall"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/smithy-go/logging"
@@ -20,6 +22,18 @@ type SubcommandInfo struct {
 }
 
 var subcommands map[string]SubcommandInfo = map[string]SubcommandInfo{
+	"check-integrity": {
+		Description: "Check the sets maintained by the importers against the references they mirror, and optionally fix them.",
+		Exec:        checkIntegrity,
+	},
+	"import-all": {
+		Description: "Import every dataset in a directory in dependency order.",
+		Exec:        importAll,
+	},
+	"import-applications": {
+		Description: "Import application data from a JSON file after checking it against the built-in definitions.",
+		Exec:        importApplications,
+	},
 	"import-crops": {
 		Description: "Import crop data from a JSON file.",
 		Exec:        importCrops,
@@ -28,18 +42,50 @@ var subcommands map[string]SubcommandInfo = map[string]SubcommandInfo{
 		Description: "Import ingredient data from a JSON file. Resistances must be imported first.",
 		Exec:        importIngredients,
 	},
+	"import-intended-users": {
+		Description: "Import intended user data from a JSON file after checking it against the built-in definitions.",
+		Exec:        importIntendedUsers,
+	},
+	"import-labels": {
+		Description: "Import label data from a JSON file. Ingredients, registrants and pesticide types should be imported first.",
+		Exec:        importLabels,
+	},
 	"import-pests": {
 		Description: "Import pest data from a JSON file.",
 		Exec:        importPests,
 	},
+	"import-pesticide-types": {
+		Description: "Import pesticide type data from a JSON file.",
+		Exec:        importPesticideTypes,
+	},
 	"import-registrants": {
 		Description: "Import registrant data from a JSON file.",
 		Exec:        importRegistrants,
 	},
+	"import-resistance-sources": {
+		Description: "Import resistance source classifications, e.g. FRAC, from a JSON file.",
+		Exec:        importResistanceSources,
+	},
 	"import-resistances": {
 		Description: "Import resistance data from a JSON file.",
 		Exec:        importResistances,
 	},
+	"import-signal-words": {
+		Description: "Import signal word data from a JSON file after checking it against the built-in definitions.",
+		Exec:        importSignalWords,
+	},
+	"import-states": {
+		Description: "Import state data from a JSON file after checking it against the built-in definitions.",
+		Exec:        importStates,
+	},
+	"rotation-advice": {
+		Description: "Advise on rotating resistance groups over a season's applications, or serve the advice over HTTP.",
+		Exec:        rotationAdvice,
+	},
+	"verify-enums": {
+		Description: "Verify the built-in enumerations against the dataset files.",
+		Exec:        verifyEnums,
+	},
 }
 
 func main() {
@@ -121,7 +167,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	ctx := context.Background()
+	// Interrupting a subcommand cancels its context, so that imports can stop cleanly and save their progress.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	ctx = context.WithValue(ctx, PicolCtxDynamoDBTablePrefix, fmt.Sprintf("%s%s%s", *tablePrefix, *project, *environment))
 	ctx = context.WithValue(ctx, PicolCtxAWSConfig, awsConfig)
 
@@ -138,5 +185,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	os.Exit(subcommand.Exec(ctx, cliFlags.Args()[1:]))
+	exitCode := subcommand.Exec(ctx, cliFlags.Args()[1:])
+	stop()
+	os.Exit(exitCode)
 }
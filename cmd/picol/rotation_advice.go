@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/corbaltcode/picol/internal/rotation"
+)
+
+func rotationAdvice(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("rotation-advice", flag.ExitOnError)
+	cropId := flags.Int("crop", 0, "Id of the season's crop. Alternative labels are suggested when both -crop and -pest are given.")
+	pestId := flags.Int("pest", 0, "Id of the season's pest.")
+	maxAlternatives := flags.Int("max-alternatives", rotation.DefaultMaxAlternatives, "Largest number of alternative labels to suggest with -crop and -pest.")
+	jsonOutput := flags.Bool("json", false, "Write the advice as JSON.")
+	listen := flags.String("listen", "", "Serve rotation advice over HTTP at this address, e.g. \":8080\", instead of advising on applications given as arguments.")
+	help := flags.Bool("help", false, "Show help.")
+
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Show the resistance groups used by a season's applications, warn about consecutive applications from the same group and suggest labels from other groups registered for the crop and pest.\n")
+		fmt.Fprintf(out, "Usage: %s rotation-advice [options] [-crop <id> -pest <id>] <application>...\n", os.Args[0])
+		fmt.Fprintf(out, "       %s rotation-advice -listen <address>\n", os.Args[0])
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Each application is label=<id> or ingredients=<id>[,<id>...], in the order they were applied.\n")
+		fmt.Fprintf(out, "With -listen, POST {\"Applications\": [{\"LabelId\": <id>}, {\"IngredientIds\": [<id>, ...]}], \"CropId\": <id>, \"PestId\": <id>} to /v1/rotation-advice.\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if *help {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return 0
+	}
+
+	// Without a crop and pest there is nothing to suggest alternatives for, unless they were asked for explicitly.
+	if *cropId == 0 && *pestId == 0 && !flagSet(flags, "max-alternatives") {
+		*maxAlternatives = 0
+	}
+
+	var applications []rotation.Application
+	for _, arg := range flags.Args() {
+		application, err := parseApplication(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid application %s: %s\n", arg, err)
+			flags.Usage()
+			return 1
+		}
+		applications = append(applications, application)
+	}
+
+	if *listen == "" && len(applications) == 0 {
+		fmt.Fprintf(os.Stderr, "No applications specified.\n")
+		flags.Usage()
+		return 1
+	}
+
+	if *listen != "" && (len(applications) > 0 || *cropId != 0 || *pestId != 0) {
+		fmt.Fprintf(os.Stderr, "Applications, -crop and -pest cannot be given with -listen, as each request gives its own.\n")
+		flags.Usage()
+		return 1
+	}
+
+	client := dynamodb.NewFromConfig(CtxGetAWSConfig(ctx))
+	catalog, err := rotation.LoadCatalog(ctx, client, CtxGetDynamoDBTablePrefix(ctx))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading resistance groups, labels, crops and pests: %s\n", err)
+		return 1
+	}
+
+	if *listen != "" {
+		return serveRotationAdvice(ctx, *listen, catalog)
+	}
+
+	advice, err := catalog.Advise(applications, rotation.Registration{CropId: *cropId, PestId: *pestId}, *maxAlternatives)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error advising on applications: %s\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(advice)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing advice: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	printRotationAdvice(advice)
+	return 0
+}
+
+// parseApplication parses an application argument: label=<id> or ingredients=<id>[,<id>...].
+func parseApplication(arg string) (rotation.Application, error) {
+	kind, value, found := strings.Cut(arg, "=")
+	if !found {
+		return rotation.Application{}, errors.New("expected label=<id> or ingredients=<id>[,<id>...]")
+	}
+
+	var ids []int
+	for _, s := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(s)
+		if err != nil || id <= 0 {
+			return rotation.Application{}, fmt.Errorf("%q is not an id", s)
+		}
+		ids = append(ids, id)
+	}
+
+	switch kind {
+	case "label":
+		if len(ids) != 1 {
+			return rotation.Application{}, errors.New("an application has a single label")
+		}
+		return rotation.Application{LabelId: ids[0]}, nil
+	case "ingredients":
+		return rotation.Application{IngredientIds: ids}, nil
+	default:
+		return rotation.Application{}, fmt.Errorf("unknown application kind %q", kind)
+	}
+}
+
+func printRotationAdvice(advice rotation.Advice) {
+	for i, ag := range advice.Applications {
+		var what string
+		if ag.Label != nil {
+			what = fmt.Sprintf("label %d %s (EPA %s)", ag.Label.Id, ag.Label.Name, ag.Label.EpaNumber)
+		} else {
+			what = fmt.Sprintf("ingredients %s", joinInts(ag.IngredientIds))
+		}
+		fmt.Printf("Application %d: %s: %s\n", i+1, what, joinGroups(ag.Groups))
+	}
+
+	fmt.Printf("\nGroups used:\n")
+	for _, use := range advice.Groups {
+		fmt.Printf("  %s: %d application(s)\n", use.Group, use.Applications)
+	}
+
+	if len(advice.Warnings) > 0 {
+		fmt.Printf("\nWarnings:\n")
+		for _, warning := range advice.Warnings {
+			fmt.Printf("  %s\n", warning)
+		}
+	}
+
+	if len(advice.Alternatives) > 0 {
+		fmt.Printf("\nAlternatives registered for %s and %s:\n", advice.Crop, advice.Pest)
+		for _, label := range advice.Alternatives {
+			fmt.Printf("  label %d %s (EPA %s): %s\n", label.Id, label.Name, label.EpaNumber, joinGroups(label.Groups))
+		}
+	}
+}
+
+// serveRotationAdvice serves the rotation advice endpoint until the context is cancelled.
+func serveRotationAdvice(ctx context.Context, address string, catalog *rotation.Catalog) int {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/rotation-advice", rotation.NewHandler(catalog))
+	server := &http.Server{Addr: address, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving rotation advice at %s/v1/rotation-advice\n", address)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error serving rotation advice: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func joinGroups(groups []rotation.Group) string {
+	if len(groups) == 0 {
+		return "no known resistance group"
+	}
+	s := make([]string, len(groups))
+	for i, group := range groups {
+		s[i] = group.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func joinInts(ints []int) string {
+	s := make([]string, len(ints))
+	for i, n := range ints {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(flags *flag.FlagSet, name string) bool {
+	found := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func verifyEnums(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("verify-enums", flag.ExitOnError)
+	help := flags.Bool("help", false, "Show help.")
+
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Verify the built-in enumerations against the newest dataset files in a directory.\n")
+		fmt.Fprintf(out, "Usage: %s verify-enums [options] [directory]\n", os.Args[0])
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "The directory defaults to \"datasets\".\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if *help {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return 0
+	}
+
+	args = flags.Args()
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", args[1])
+		flags.Usage()
+		return 1
+	}
+
+	dir := "datasets"
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	result := 0
+	for _, check := range enumChecks {
+		filename, err := latestDatasetFile(dir, check.Entity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding dataset: %s\n", err)
+			result = 1
+			continue
+		}
+
+		diffs, err := check.Check(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking %s: %s\n", filename, err)
+			result = 1
+			continue
+		}
+
+		if len(diffs) == 0 {
+			fmt.Printf("%s: OK\n", filename)
+			continue
+		}
+
+		fmt.Printf("%s: %d difference(s)\n", filename, len(diffs))
+		for _, diff := range diffs {
+			fmt.Printf("  %s\n", diff)
+		}
+		result = 1
+	}
+
+	return result
+}
@@ -11,3 +11,8 @@ type Application struct {
 	// Single character application code.
 	Code string
 }
+
+// Validate checks the application against the documented constraints of its fields.
+func (a Application) Validate() error {
+	return checkLength("Code", a.Code, "a single character", 1)
+}
@@ -79,3 +79,8 @@ func (ad *AwfulDate) UnmarshalJSON(b []byte) error {
 	ad.Day = newAD.Day
 	return nil
 }
+
+// ISODate returns the date in the format YYYY-MM-DD.
+func (ad AwfulDate) ISODate() string {
+	return fmt.Sprintf("%04d-%02d-%02d", ad.Year, ad.Month, ad.Day)
+}
@@ -14,3 +14,21 @@ type Crop struct {
 	// Notes about the crop.
 	Notes string
 }
+
+// Validate checks the crop against the documented constraints of its fields.
+func (c Crop) Validate() error {
+	return checkLength("Code", c.Code, "four characters", 4)
+}
+
+// Normalize returns the crop with its text normalized.
+func (c Crop) Normalize() Crop {
+	c.Name = normalizeText(c.Name)
+	c.Code = normalizeText(c.Code)
+	c.Notes = normalizeNotes(c.Notes)
+	return c
+}
+
+// SortName returns the key that the crop sorts by.
+func (c Crop) SortName() string {
+	return SortKey(c.Name)
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ResponseDecoder reads a version 1 API Response from a stream one Data element at a time, so that large responses
+// never have to be held in memory.
+//
+// Error and Message are set as they are read. A response with Error set is rejected with an *ErrorResponse instead of
+// yielding its Data. The envelope fields may appear in any order; if Error follows Data, the Data elements have already
+// been returned by the time Next reports the ErrorResponse.
+type ResponseDecoder[T any] struct {
+	// The Error field of the response.
+	Error bool
+
+	// The Message field of the response.
+	Message string
+
+	// Whether the response has an Errors field, which the rejects files written by imports have and API responses do
+	// not. Only set once the field has been read.
+	HasErrors bool
+
+	decoder *json.Decoder
+	started bool
+	inData  bool
+	done    bool
+}
+
+// ErrorResponse is the error returned by ResponseDecoder for a response with Error set, e.g. one captured from a
+// failed API call.
+type ErrorResponse struct {
+	// The Message field of the response.
+	Message string
+}
+
+func (e *ErrorResponse) Error() string {
+	if e.Message == "" {
+		return "the response is an API error"
+	}
+	return fmt.Sprintf("the response is an API error: %s", e.Message)
+}
+
+// NewResponseDecoder creates a ResponseDecoder that reads from r.
+func NewResponseDecoder[T any](r io.Reader) *ResponseDecoder[T] {
+	return &ResponseDecoder[T]{decoder: json.NewDecoder(r)}
+}
+
+// Next decodes the next element of Data. It returns io.EOF once the whole response has been read, or an
+// *ErrorResponse if the response has Error set.
+func (rd *ResponseDecoder[T]) Next() (T, error) {
+	var element T
+
+	if rd.done {
+		return element, rd.end()
+	}
+
+	if !rd.started {
+		rd.started = true
+		err := rd.expectDelim('{')
+		if err != nil {
+			return element, err
+		}
+	}
+
+	for {
+		if rd.inData {
+			if rd.decoder.More() {
+				err := rd.decoder.Decode(&element)
+				if err != nil {
+					return element, fmt.Errorf("decoding Data element: %w", err)
+				}
+				return element, nil
+			}
+
+			// Consume the closing bracket of Data.
+			_, err := rd.token()
+			if err != nil {
+				return element, err
+			}
+			rd.inData = false
+		}
+
+		if !rd.decoder.More() {
+			// Consume the closing brace of the response.
+			_, err := rd.token()
+			if err != nil {
+				return element, err
+			}
+			rd.done = true
+			return element, rd.end()
+		}
+
+		err := rd.readField()
+		if err != nil {
+			return element, err
+		}
+	}
+}
+
+// readField reads the next field of the response envelope. For Data, it only reads the opening bracket.
+func (rd *ResponseDecoder[T]) readField() error {
+	token, err := rd.token()
+	if err != nil {
+		return err
+	}
+
+	name, ok := token.(string)
+	if !ok {
+		return fmt.Errorf("expected a field name, found %v", token)
+	}
+
+	// Field names are matched case-insensitively, as encoding/json does.
+	switch {
+	case strings.EqualFold(name, "Error"):
+		return rd.decoder.Decode(&rd.Error)
+	case strings.EqualFold(name, "Message"):
+		return rd.decoder.Decode(&rd.Message)
+	case strings.EqualFold(name, "Errors"):
+		rd.HasErrors = true
+		var ignored json.RawMessage
+		return rd.decoder.Decode(&ignored)
+	case strings.EqualFold(name, "Data") && rd.Error:
+		// The Data of an error response is never returned, so it is skipped to reach the Message.
+		var ignored json.RawMessage
+		return rd.decoder.Decode(&ignored)
+	case strings.EqualFold(name, "Data"):
+		token, err := rd.token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			// "Data": null
+			return nil
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("expected Data to be an array, found %v", token)
+		}
+		rd.inData = true
+		return nil
+	default:
+		var ignored json.RawMessage
+		return rd.decoder.Decode(&ignored)
+	}
+}
+
+// end returns the error that Next reports once the whole response has been read.
+func (rd *ResponseDecoder[T]) end() error {
+	if rd.Error {
+		return &ErrorResponse{Message: rd.Message}
+	}
+	return io.EOF
+}
+
+// token reads the next JSON token. The response is incomplete if the input ends before the closing brace, so io.EOF
+// is reported as io.ErrUnexpectedEOF.
+func (rd *ResponseDecoder[T]) token() (json.Token, error) {
+	token, err := rd.decoder.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return token, err
+}
+
+func (rd *ResponseDecoder[T]) expectDelim(expected json.Delim) error {
+	token, err := rd.token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v, found %v", expected, token)
+	}
+
+	return nil
+}
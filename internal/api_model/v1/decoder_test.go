@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeAll reads every Data element of a response and returns them with the error that ended the decoding.
+func decodeAll(input string) ([]Crop, *ResponseDecoder[Crop], error) {
+	decoder := NewResponseDecoder[Crop](strings.NewReader(input))
+
+	var crops []Crop
+	for {
+		crop, err := decoder.Next()
+		if err != nil {
+			return crops, decoder, err
+		}
+		crops = append(crops, crop)
+	}
+}
+
+func TestResponseDecoderYieldsData(t *testing.T) {
+	apple := Crop{Id: 1, Name: "APPLE"}
+	pear := Crop{Id: 2, Name: "PEAR"}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []Crop
+	}{
+		{"envelope first", `{"Error": false, "Message": "", "Data": [{"Id": 1, "Name": "APPLE"}, {"Id": 2, "Name": "PEAR"}]}`, []Crop{apple, pear}},
+		{"data first", `{"Data": [{"Id": 1, "Name": "APPLE"}], "Message": "", "Error": false}`, []Crop{apple}},
+		{"unknown fields", `{"Version": {"Major": 1}, "Data": [{"Id": 2, "Name": "PEAR"}], "Extra": [1, 2]}`, []Crop{pear}},
+		{"case-insensitive field names", `{"error": false, "DATA": [{"id": 1, "name": "APPLE"}]}`, []Crop{apple}},
+		{"empty data", `{"Error": false, "Data": []}`, nil},
+		{"null data", `{"Error": false, "Data": null}`, nil},
+		{"no data", `{"Error": false}`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crops, decoder, err := decodeAll(test.input)
+			if err != io.EOF {
+				t.Fatalf("got error %v, want io.EOF", err)
+			}
+			if !reflect.DeepEqual(crops, test.want) {
+				t.Errorf("got %v, want %v", crops, test.want)
+			}
+
+			// Once the response has been read, Next keeps reporting its end.
+			if _, err := decoder.Next(); err != io.EOF {
+				t.Errorf("got error %v after the end, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestResponseDecoderReadsEnvelope(t *testing.T) {
+	_, decoder, err := decodeAll(`{"Message": "2 crops", "Data": [], "Errors": [{"Key": "Id=1"}]}`)
+	if err != io.EOF {
+		t.Fatalf("got error %v, want io.EOF", err)
+	}
+	if decoder.Error || decoder.Message != "2 crops" || !decoder.HasErrors {
+		t.Errorf("got Error %t, Message %q and HasErrors %t, want false, \"2 crops\" and true", decoder.Error, decoder.Message, decoder.HasErrors)
+	}
+}
+
+func TestResponseDecoderRejectsErrorResponses(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+
+		// The elements returned before the error.
+		want []Crop
+	}{
+		{"error before data", `{"Error": true, "Message": "boom", "Data": [{"Id": 1, "Name": "APPLE"}]}`, nil},
+		{"error after data", `{"Data": [{"Id": 1, "Name": "APPLE"}], "Error": true, "Message": "boom"}`, []Crop{{Id: 1, Name: "APPLE"}}},
+		{"message before error", `{"Message": "boom", "Data": null, "Error": true}`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crops, _, err := decodeAll(test.input)
+
+			var errorResponse *ErrorResponse
+			if !errors.As(err, &errorResponse) {
+				t.Fatalf("got error %v, want an *ErrorResponse", err)
+			}
+			if errorResponse.Message != "boom" {
+				t.Errorf("got message %q, want \"boom\"", errorResponse.Message)
+			}
+			if !reflect.DeepEqual(crops, test.want) {
+				t.Errorf("got %v before the error, want %v", crops, test.want)
+			}
+		})
+	}
+}
+
+func TestResponseDecoderRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ``},
+		{"not an object", `[{"Id": 1}]`},
+		{"truncated in data", `{"Data": [{"Id": 1, "Name": "APPLE"}, {"Id": 2`},
+		{"truncated after data", `{"Data": [{"Id": 1, "Name": "APPLE"}]`},
+		{"truncated in envelope", `{"Error": false, "Mess`},
+		{"data not an array", `{"Data": {"Id": 1}}`},
+		{"element of the wrong type", `{"Data": [{"Id": "one"}]}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// A response that ends early is incomplete, not merely over.
+			_, _, err := decodeAll(test.input)
+			if err == nil || err == io.EOF {
+				t.Fatalf("got error %v, want a decoding error", err)
+			}
+
+			var errorResponse *ErrorResponse
+			if errors.As(err, &errorResponse) {
+				t.Errorf("got an ErrorResponse for malformed input: %v", err)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package v1
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Ingredient represents a version 1 API data object for pesticide ingredient information.
 type Ingredient struct {
 	// The unique PICOL identifier for the ingredient.
@@ -14,6 +19,59 @@ type Ingredient struct {
 	// Notes about the ingredient.
 	Notes string
 
-	// Resistance information about the ingredient.
+	// Resistance information about the ingredient. For an ingredient in several resistance groups, this is the primary
+	// group.
 	Resistance Resistance
+
+	// Every resistance group of the ingredient, with the primary group first. Omitted unless the ingredient is in
+	// more than one group, so that clients that only know Resistance are unaffected.
+	Resistances []Resistance `json:",omitempty"`
+}
+
+// ResistanceGroups returns the resistance groups of the ingredient, with the primary group first and without
+// duplicates. The null resistance is left out, so an ingredient without resistance information has none.
+func (i Ingredient) ResistanceGroups() []Resistance {
+	var groups []Resistance
+	seen := map[int]bool{}
+	for _, resistance := range append([]Resistance{i.Resistance}, i.Resistances...) {
+		if resistance.IsNull() || seen[resistance.Id] {
+			continue
+		}
+		seen[resistance.Id] = true
+		groups = append(groups, resistance)
+	}
+	return groups
+}
+
+// Validate checks the ingredient and its resistance against the documented constraints of their fields.
+func (i Ingredient) Validate() error {
+	errs := []error{
+		checkDigits("Code", i.Code, "six digits", 6),
+		nested("Resistance", i.Resistance.Validate()),
+	}
+	for j, resistance := range i.Resistances {
+		errs = append(errs, nested(fmt.Sprintf("Resistances[%d]", j), resistance.Validate()))
+	}
+	return errors.Join(errs...)
+}
+
+// Normalize returns the ingredient and its resistance with their text normalized.
+func (i Ingredient) Normalize() Ingredient {
+	i.Name = normalizeText(i.Name)
+	i.Code = normalizeText(i.Code)
+	i.Notes = normalizeNotes(i.Notes)
+	i.Resistance = i.Resistance.Normalize()
+	if i.Resistances != nil {
+		resistances := make([]Resistance, len(i.Resistances))
+		for j, resistance := range i.Resistances {
+			resistances[j] = resistance.Normalize()
+		}
+		i.Resistances = resistances
+	}
+	return i
+}
+
+// SortName returns the key that the ingredient sorts by.
+func (i Ingredient) SortName() string {
+	return SortKey(i.Name)
 }
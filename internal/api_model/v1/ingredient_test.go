@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIngredientResistanceGroups(t *testing.T) {
+	null := Resistance{Id: NullResistanceId}
+	frac3 := Resistance{Id: 3, Source: "FRAC", Code: "3"}
+	frac7 := Resistance{Id: 7, Source: "FRAC", Code: "7"}
+
+	tests := []struct {
+		name       string
+		ingredient Ingredient
+		want       []Resistance
+	}{
+		{"one group", Ingredient{Resistance: frac3}, []Resistance{frac3}},
+		{"null resistance", Ingredient{Resistance: null}, nil},
+		{"several groups", Ingredient{Resistance: frac7, Resistances: []Resistance{frac7, frac3}}, []Resistance{frac7, frac3}},
+		{"primary group not listed", Ingredient{Resistance: frac7, Resistances: []Resistance{frac3}}, []Resistance{frac7, frac3}},
+		{"null primary group", Ingredient{Resistance: null, Resistances: []Resistance{frac3, null, frac3}}, []Resistance{frac3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.ingredient.ResistanceGroups(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got groups %v, want %v", got, test.want)
+			}
+		})
+	}
+}
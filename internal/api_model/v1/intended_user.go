@@ -11,3 +11,8 @@ type IntendedUser struct {
 	// Single character intended user code.
 	Code string
 }
+
+// Validate checks the intended user against the documented constraints of its fields.
+func (iu IntendedUser) Validate() error {
+	return checkLength("Code", iu.Code, "a single character", 1)
+}
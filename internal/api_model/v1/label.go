@@ -1,5 +1,10 @@
 package v1
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Label represents a version 1 API data object for pesticide label information.
 type Label struct {
 	// The unique PICOL identifier for the pesticide label.
@@ -20,6 +25,12 @@ type Label struct {
 	// The type(s) of this pesticide.
 	PesticideTypes []PesticideType
 
+	// The crops the pesticide is registered for.
+	Crops []Crop `json:",omitempty"`
+
+	// The pests the pesticide is registered against.
+	Pests []Pest `json:",omitempty"`
+
 	// The registrant of the pesticide.
 	Registrant Registrant
 
@@ -62,3 +73,57 @@ type Label struct {
 	// EPA Section 18 emergency exemption.
 	Section18 string
 }
+
+// Validate checks the label and the records embedded in it against the documented constraints of their fields.
+func (l Label) Validate() error {
+	errs := []error{
+		nested("IntendedUser", l.IntendedUser.Validate()),
+		nested("Registrant", l.Registrant.Validate()),
+	}
+	for i, ingredient := range l.Ingredients {
+		errs = append(errs, nested(fmt.Sprintf("Ingredients[%d]", i), ingredient.Validate()))
+	}
+	for i, pesticideType := range l.PesticideTypes {
+		errs = append(errs, nested(fmt.Sprintf("PesticideTypes[%d]", i), pesticideType.Validate()))
+	}
+	for i, crop := range l.Crops {
+		errs = append(errs, nested(fmt.Sprintf("Crops[%d]", i), crop.Validate()))
+	}
+	for i, pest := range l.Pests {
+		errs = append(errs, nested(fmt.Sprintf("Pests[%d]", i), pest.Validate()))
+	}
+	for i, stateRecord := range l.StateRecords {
+		errs = append(errs, nested(fmt.Sprintf("StateRecords[%d]", i), stateRecord.Validate()))
+	}
+	return errors.Join(errs...)
+}
+
+// Normalize returns the label and its state records with their text normalized. The other embedded records are only
+// referred to by id, so they are left as they are.
+func (l Label) Normalize() Label {
+	l.Name = normalizeText(l.Name)
+	l.EpaNumber = normalizeText(l.EpaNumber)
+	l.Sln = normalizeText(l.Sln)
+	l.SlnName = normalizeText(l.SlnName)
+	l.Supplemental = normalizeText(l.Supplemental)
+	l.SupplementalName = normalizeText(l.SupplementalName)
+	l.Formulation = normalizeText(l.Formulation)
+	l.SignalWord = normalizeText(l.SignalWord)
+	l.Usage = normalizeNotes(l.Usage)
+	l.Section18 = normalizeText(l.Section18)
+
+	if l.StateRecords != nil {
+		stateRecords := make([]StateRecord, len(l.StateRecords))
+		for i, stateRecord := range l.StateRecords {
+			stateRecords[i] = stateRecord.Normalize()
+		}
+		l.StateRecords = stateRecords
+	}
+
+	return l
+}
+
+// SortName returns the key that the label sorts by.
+func (l Label) SortName() string {
+	return SortKey(l.Name)
+}
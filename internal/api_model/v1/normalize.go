@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeText trims and collapses whitespace and applies Unicode normalization form C.
+func normalizeText(s string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(s), " "))
+}
+
+// normalizeNotes trims whitespace and applies Unicode normalization form C. Unlike normalizeText, it keeps line breaks
+// and spacing within the text.
+func normalizeNotes(s string) string {
+	return norm.NFC.String(strings.TrimSpace(s))
+}
+
+// canonicalURL returns a website as an absolute https URL with a lowercase host, e.g. "https://www.example.com" for
+// "WWW.EXAMPLE.COM". A website without a scheme is taken to start with a host name. It returns an error unless the
+// website is a host name or an http or https URL.
+func canonicalURL(website string) (string, error) {
+	s := strings.TrimSpace(website)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	if u.User != nil {
+		return "", errors.New("URL contains user information")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !validHostName(host) {
+		return "", fmt.Errorf("invalid host name %q", host)
+	}
+
+	u.Scheme = "https"
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
+
+// validHostName reports whether host is a fully qualified DNS name, e.g. "www.example.com".
+func validHostName(host string) bool {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+
+	// Top-level domains are never numeric, which rules out IP addresses.
+	tld := labels[len(labels)-1]
+	return strings.IndexFunc(tld, unicode.IsLetter) >= 0
+}
+
+// SortKey returns the key that a name sorts by: case-folded, with punctuation and symbols treated as spaces, e.g.
+// "e 11 tetradecen 1 ol acetate" for "(E)-11-TETRADECEN-1-OL ACETATE".
+func SortKey(name string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, norm.NFC.String(name))
+
+	return cases.Fold().String(strings.Join(strings.Fields(key), " "))
+}
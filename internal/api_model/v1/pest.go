@@ -14,3 +14,21 @@ type Pest struct {
 	// Notes about the pest.
 	Notes string
 }
+
+// Validate checks the pest against the documented constraints of its fields.
+func (p Pest) Validate() error {
+	return checkLength("Code", p.Code, "four or five characters", 4, 5)
+}
+
+// Normalize returns the pest with its text normalized.
+func (p Pest) Normalize() Pest {
+	p.Name = normalizeText(p.Name)
+	p.Code = normalizeText(p.Code)
+	p.Notes = normalizeNotes(p.Notes)
+	return p
+}
+
+// SortName returns the key that the pest sorts by.
+func (p Pest) SortName() string {
+	return SortKey(p.Name)
+}
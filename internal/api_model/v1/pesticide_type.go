@@ -11,3 +11,20 @@ type PesticideType struct {
 	// The three- or four-character pesticide type code.
 	Code string
 }
+
+// Validate checks the pesticide type against the documented constraints of its fields.
+func (pt PesticideType) Validate() error {
+	return checkLength("Code", pt.Code, "three or four characters", 3, 4)
+}
+
+// Normalize returns the pesticide type with its text normalized.
+func (pt PesticideType) Normalize() PesticideType {
+	pt.Name = normalizeText(pt.Name)
+	pt.Code = normalizeText(pt.Code)
+	return pt
+}
+
+// SortName returns the key that the pesticide type sorts by.
+func (pt PesticideType) SortName() string {
+	return SortKey(pt.Name)
+}
@@ -1,5 +1,7 @@
 package v1
 
+import "strings"
+
 // Registrant represents a version 1 API data object for registrant information.
 type Registrant struct {
 	// The unique PICOL identifier for the registrant.
@@ -11,3 +13,45 @@ type Registrant struct {
 	// The registrant's website.
 	Website string
 }
+
+// URL returns the registrant's website as a canonical absolute https URL, e.g. "https://www.example.com" for
+// "WWW.EXAMPLE.COM". It returns "" if the registrant has no website, and an error if the website is malformed.
+func (r Registrant) URL() (string, error) {
+	if strings.TrimSpace(r.Website) == "" {
+		return "", nil
+	}
+	return canonicalURL(r.Website)
+}
+
+// Placeholder reports whether the registrant is a placeholder that reserves its id rather than a real registrant, e.g.
+// "-RESERVED-". Real registrants whose names are marked as reserved, e.g. "CRODA INC -RESERVED-", are not
+// placeholders.
+func (r Registrant) Placeholder() bool {
+	switch SortKey(r.Name) {
+	case "reserved", "reserved for picol use only":
+		return true
+	}
+	return false
+}
+
+// Validate checks the registrant against the documented constraints of its fields: the website, if any, must be a
+// host name or an http or https URL.
+func (r Registrant) Validate() error {
+	_, err := r.URL()
+	if err != nil {
+		return &ValidationError{Field: "Website", Value: r.Website, Constraint: "a host name or an http or https URL"}
+	}
+	return nil
+}
+
+// Normalize returns the registrant with its text normalized. The website is left as it is, since it is kept as
+// imported alongside its canonical URL.
+func (r Registrant) Normalize() Registrant {
+	r.Name = normalizeText(r.Name)
+	return r
+}
+
+// SortName returns the key that the registrant sorts by.
+func (r Registrant) SortName() string {
+	return SortKey(r.Name)
+}
@@ -0,0 +1,24 @@
+package v1
+
+import "testing"
+
+func TestRegistrantPlaceholder(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"-RESERVED-", true},
+		{" -reserved- ", true},
+		{"RESERVED", true},
+		{"-RESERVED FOR PICOL USE ONLY-", true},
+		{"CRODA INC -RESERVED-", false},
+		{"RESERVED CHEMICALS LLC", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := (Registrant{Name: test.name}).Placeholder(); got != test.want {
+			t.Errorf("Registrant{Name: %q}.Placeholder() = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
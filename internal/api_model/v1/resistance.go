@@ -1,5 +1,14 @@
 package v1
 
+import (
+	"fmt"
+	"strconv"
+)
+
+// NullResistanceId is the id of the null resistance, which version 1 of the API gives ingredients that have no
+// resistance. It has no source, code or method of action.
+const NullResistanceId = 1
+
 // Resistance represents a version 1 API data object for resistance information.
 type Resistance struct {
 	// The unique PICOL identifier for the resistance.
@@ -14,3 +23,37 @@ type Resistance struct {
 	// The method of action for the resistance.
 	MethodOfAction string
 }
+
+// IsNull reports whether r is the null resistance, which stands for no resistance rather than a real resistance
+// group.
+func (r Resistance) IsNull() bool {
+	return r.Id == NullResistanceId && r.isEmpty()
+}
+
+// isEmpty reports whether r has no source, code or method of action.
+func (r Resistance) isEmpty() bool {
+	return r.Source == "" && r.Code == "" && r.MethodOfAction == ""
+}
+
+// Validate checks the resistance against the documented constraints of its fields. Codes are not checked, as
+// upstream codes such as "16.1" are not strictly alphanumeric. The null resistance is valid, but only it may be empty
+// and only it may have NullResistanceId.
+func (r Resistance) Validate() error {
+	switch {
+	case r.IsNull():
+		return nil
+	case r.isEmpty():
+		return &ValidationError{Field: "Id", Value: strconv.Itoa(r.Id), Message: fmt.Sprintf("a resistance with no source, code or method of action must have Id %d (the null resistance)", NullResistanceId)}
+	case r.Id == NullResistanceId:
+		return &ValidationError{Field: "Id", Value: strconv.Itoa(r.Id), Message: "a resistance with a source, code or method of action cannot have the id of the null resistance"}
+	}
+	return checkLength("Source", r.Source, "four characters", 4)
+}
+
+// Normalize returns the resistance with its text normalized.
+func (r Resistance) Normalize() Resistance {
+	r.Source = normalizeText(r.Source)
+	r.Code = normalizeText(r.Code)
+	r.MethodOfAction = normalizeText(r.MethodOfAction)
+	return r
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResistanceSource describes an organization that classifies pesticides into resistance groups, e.g. FRAC, and the
+// edition of its classification. Version 1 of the API has no resistance source endpoint; classification files use the
+// same response layout as the datasets so that they can be imported the same way.
+type ResistanceSource struct {
+	// Four-character source code, as used by the Source field of resistances, e.g. "FRAC".
+	Source string
+
+	// The name of the organization, e.g. "Fungicide Resistance Action Committee".
+	Name string
+
+	// The organization's website.
+	Url string
+
+	// The version of the classification scheme, as published by the organization.
+	Version string
+
+	// The publication date of the classification, in YYYY-MM-DD form.
+	PublishedAt string
+
+	// The group codes defined by the classification, e.g. "M1".
+	Codes []string
+}
+
+// URL returns the source's website as a canonical absolute https URL. It returns "" if the source has no website, and
+// an error if the website is malformed.
+func (s ResistanceSource) URL() (string, error) {
+	if strings.TrimSpace(s.Url) == "" {
+		return "", nil
+	}
+	return canonicalURL(s.Url)
+}
+
+// HasCode reports whether code is one of the group codes of the classification.
+func (s ResistanceSource) HasCode(code string) bool {
+	for _, c := range s.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the source against the documented constraints of its fields.
+func (s ResistanceSource) Validate() error {
+	errs := []error{checkLength("Source", s.Source, "four characters", 4)}
+
+	if _, err := s.URL(); err != nil {
+		errs = append(errs, &ValidationError{Field: "Url", Value: s.Url, Constraint: "a host name or an http or https URL"})
+	}
+
+	if s.PublishedAt != "" {
+		if _, err := time.Parse(time.DateOnly, s.PublishedAt); err != nil {
+			errs = append(errs, &ValidationError{Field: "PublishedAt", Value: s.PublishedAt, Constraint: "a YYYY-MM-DD date"})
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, code := range s.Codes {
+		if code == "" || seen[code] {
+			errs = append(errs, &ValidationError{Field: fmt.Sprintf("Codes[%d]", i), Value: code, Constraint: "a distinct, non-empty code"})
+		}
+		seen[code] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// Normalize returns the source with its text normalized.
+func (s ResistanceSource) Normalize() ResistanceSource {
+	s.Source = normalizeText(s.Source)
+	s.Name = normalizeText(s.Name)
+	s.Url = normalizeText(s.Url)
+	s.Version = normalizeText(s.Version)
+	s.PublishedAt = normalizeText(s.PublishedAt)
+	if s.Codes != nil {
+		codes := make([]string, len(s.Codes))
+		for i, code := range s.Codes {
+			codes[i] = normalizeText(code)
+		}
+		s.Codes = codes
+	}
+	return s
+}
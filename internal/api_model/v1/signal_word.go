@@ -11,3 +11,8 @@ type SignalWord struct {
 	// The single-character signal word code.
 	Code string
 }
+
+// Validate checks the signal word against the documented constraints of its fields.
+func (sw SignalWord) Validate() error {
+	return checkLength("Code", sw.Code, "a single character", 1)
+}
@@ -8,3 +8,9 @@ type State struct {
 	// The full name of the state.
 	Name string
 }
+
+// Validate checks the state against the documented constraints of its fields. States have none, so it always
+// returns nil.
+func (s State) Validate() error {
+	return nil
+}
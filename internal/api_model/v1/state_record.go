@@ -26,3 +26,17 @@ type StateRecord struct {
 	// Indicates whether this is approved for use on industrial hemp production under WA ESSB 6206.
 	Essb6206 bool
 }
+
+// Validate checks the state record against the documented constraints of its fields. State records have none, so it
+// always returns nil.
+func (sr StateRecord) Validate() error {
+	return nil
+}
+
+// Normalize returns the state record with its text normalized.
+func (sr StateRecord) Normalize() StateRecord {
+	sr.Name = normalizeText(sr.Name)
+	sr.AgencyId = normalizeText(sr.AgencyId)
+	sr.Version = normalizeText(sr.Version)
+	return sr
+}
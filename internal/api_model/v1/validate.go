@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ValidationError is a field that violates a documented constraint of its type. Validate methods return every
+// violation of a record, combined with errors.Join.
+type ValidationError struct {
+	// Field is the path of the field within the record, e.g. "Code" or "Ingredients[2].Code".
+	Field string
+
+	// Value is the value of the field.
+	Value string
+
+	// Constraint describes a valid value, e.g. "four characters".
+	Constraint string
+
+	// Message, if set, describes the violation in place of Constraint, for constraints that involve other fields.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %q: %s", e.Field, e.Value, e.Message)
+	}
+	return fmt.Sprintf("%s %q is not %s", e.Field, e.Value, e.Constraint)
+}
+
+// ValidationErrors returns the violations in an error returned by a Validate method.
+func ValidationErrors(err error) []*ValidationError {
+	if err == nil {
+		return nil
+	}
+
+	var violations []*ValidationError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			violations = append(violations, ValidationErrors(e)...)
+		}
+		return violations
+	}
+
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		violations = append(violations, ve)
+	}
+	return violations
+}
+
+// checkLength returns a ValidationError unless value has one of the given lengths in characters.
+func checkLength(field string, value string, constraint string, lengths ...int) error {
+	n := utf8.RuneCountInString(value)
+	for _, length := range lengths {
+		if n == length {
+			return nil
+		}
+	}
+	return &ValidationError{Field: field, Value: value, Constraint: constraint}
+}
+
+// checkDigits returns a ValidationError unless value consists of exactly n decimal digits.
+func checkDigits(field string, value string, constraint string, n int) error {
+	if len(value) != n {
+		return &ValidationError{Field: field, Value: value, Constraint: constraint}
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return &ValidationError{Field: field, Value: value, Constraint: constraint}
+		}
+	}
+	return nil
+}
+
+// nested prefixes the fields of the violations of a nested record with its path in the enclosing record.
+func nested(prefix string, err error) error {
+	violations := ValidationErrors(err)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(violations))
+	for i, ve := range violations {
+		errs[i] = &ValidationError{Field: prefix + "." + ve.Field, Value: ve.Value, Constraint: ve.Constraint, Message: ve.Message}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+// validator is a record with a Validate method.
+type validator interface {
+	Validate() error
+}
+
+// violatedFields returns the fields of the violations in an error returned by a Validate method.
+func violatedFields(err error) []string {
+	var fields []string
+	for _, ve := range ValidationErrors(err) {
+		fields = append(fields, ve.Field)
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	apple := Crop{Id: 1, Code: "APPL", Name: "APPLE"}
+	frac3 := Resistance{Id: 3, Source: "FRAC", Code: "3", MethodOfAction: "DMI fungicides"}
+	null := Resistance{Id: NullResistanceId}
+
+	tests := []struct {
+		name   string
+		record validator
+		want   []string
+	}{
+		{"application", Application{Code: "A"}, nil},
+		{"application code too long", Application{Code: "AB"}, []string{"Code"}},
+		{"crop", apple, nil},
+		{"crop code too short", Crop{Code: "APP"}, []string{"Code"}},
+		{"crop code of multibyte characters", Crop{Code: "ÄPFE"}, nil},
+		{"intended user", IntendedUser{Code: "C"}, nil},
+		{"intended user without a code", IntendedUser{}, []string{"Code"}},
+		{"pest of four characters", Pest{Code: "SCAB"}, nil},
+		{"pest of five characters", Pest{Code: "MITES"}, nil},
+		{"pest code too long", Pest{Code: "APHIDS"}, []string{"Code"}},
+		{"pesticide type of three characters", PesticideType{Code: "FUN"}, nil},
+		{"pesticide type of four characters", PesticideType{Code: "FUNG"}, nil},
+		{"pesticide type code too short", PesticideType{Code: "FU"}, []string{"Code"}},
+		{"signal word", SignalWord{Code: "D"}, nil},
+		{"signal word code too long", SignalWord{Code: "DA"}, []string{"Code"}},
+		{"state", State{}, nil},
+		{"state record", StateRecord{}, nil},
+		{"registrant without a website", Registrant{Name: "ACME"}, nil},
+		{"registrant with a host name", Registrant{Website: "www.example.com"}, nil},
+		{"registrant with a malformed website", Registrant{Website: "ftp://example.com"}, []string{"Website"}},
+		{"resistance", frac3, nil},
+		{"null resistance", null, nil},
+		{"resistance source too short", Resistance{Id: 3, Source: "FR", Code: "3"}, []string{"Source"}},
+		{"empty resistance", Resistance{Id: 3}, []string{"Id"}},
+		{"resistance with the null id", Resistance{Id: NullResistanceId, Source: "FRAC", Code: "3"}, []string{"Id"}},
+		{"resistance source", ResistanceSource{Source: "FRAC", Url: "https://www.frac.info", PublishedAt: "2024-05-01", Codes: []string{"3", "M3"}}, nil},
+		{"malformed resistance source", ResistanceSource{Source: "FRACS", Url: "mailto:frac", PublishedAt: "May 2024", Codes: []string{"3", "", "3"}}, []string{"Source", "Url", "PublishedAt", "Codes[1]", "Codes[2]"}},
+		{"ingredient", Ingredient{Code: "012345", Resistance: frac3, Resistances: []Resistance{frac3}}, nil},
+		{"ingredient code not digits", Ingredient{Code: "01234A", Resistance: null}, []string{"Code"}},
+		{"ingredient code too short", Ingredient{Code: "12345", Resistance: null}, []string{"Code"}},
+		{"ingredient with invalid resistances", Ingredient{Code: "012345", Resistance: Resistance{Id: 3}, Resistances: []Resistance{frac3, {Id: 4, Source: "FR"}}}, []string{"Resistance.Id", "Resistances[1].Source"}},
+		{"label", Label{IntendedUser: IntendedUser{Code: "C"}, Crops: []Crop{apple}}, nil},
+		{
+			"label with invalid records",
+			Label{
+				IntendedUser:   IntendedUser{Code: "CC"},
+				Registrant:     Registrant{Website: "ftp://example.com"},
+				Ingredients:    []Ingredient{{Code: "012345", Resistance: null}, {Code: "1", Resistance: frac3}},
+				PesticideTypes: []PesticideType{{Code: "F"}},
+				Crops:          []Crop{apple, {Code: "PEA"}},
+				Pests:          []Pest{{Code: "BUG"}},
+			},
+			[]string{"IntendedUser.Code", "Registrant.Website", "Ingredients[1].Code", "PesticideTypes[0].Code", "Crops[1].Code", "Pests[0].Code"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.record.Validate()
+			if got := violatedFields(err); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got violations of %q, want %q: %v", got, test.want, err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessages(t *testing.T) {
+	err := Ingredient{Code: "12345", Resistance: Resistance{Id: 3, Source: "FR"}}.Validate()
+	want := "Code \"12345\" is not six digits\nResistance.Source \"FR\" is not four characters"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+
+	err = Resistance{Id: 3}.Validate()
+	want = "Id \"3\": a resistance with no source, code or method of action must have Id 1 (the null resistance)"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,110 @@
+package ddbmodel
+
+import (
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+// IngredientFromApi converts a version 1 API ingredient into its stored form. ResistanceIds holds every resistance
+// group of the ingredient and ResistanceId the primary one. An ingredient with only the null resistance has neither.
+func IngredientFromApi(apiIngredient picolApiV1.Ingredient) Ingredient {
+	ingredient := Ingredient{
+		Id:    apiIngredient.Id,
+		Name:  apiIngredient.Name,
+		Code:  apiIngredient.Code,
+		Notes: apiIngredient.Notes,
+	}
+
+	for _, resistance := range apiIngredient.ResistanceGroups() {
+		ingredient.ResistanceIds = append(ingredient.ResistanceIds, resistance.Id)
+	}
+
+	if len(ingredient.ResistanceIds) > 0 {
+		resistanceId := ingredient.ResistanceIds[0]
+		ingredient.ResistanceId = &resistanceId
+	}
+
+	return ingredient
+}
+
+// IngredientToApi converts a stored ingredient into a version 1 API ingredient, looking up its resistance groups in
+// resistances by id. Resistance is the primary group, or the null resistance if the ingredient has none. Resistances
+// lists every group if there is more than one. Groups missing from resistances are left out.
+func IngredientToApi(ingredient Ingredient, resistances map[int]Resistance) picolApiV1.Ingredient {
+	apiIngredient := picolApiV1.Ingredient{
+		Id:         ingredient.Id,
+		Name:       ingredient.Name,
+		Code:       ingredient.Code,
+		Notes:      ingredient.Notes,
+		Resistance: picolApiV1.Resistance{Id: picolApiV1.NullResistanceId},
+	}
+
+	var groups []picolApiV1.Resistance
+	for _, id := range ingredient.AllResistanceIds() {
+		if resistance, found := resistances[id]; found {
+			groups = append(groups, ResistanceToApi(resistance))
+		}
+	}
+
+	if len(groups) > 0 {
+		apiIngredient.Resistance = groups[0]
+	}
+	if len(groups) > 1 {
+		apiIngredient.Resistances = groups
+	}
+
+	return apiIngredient
+}
+
+// ResistanceFromApi converts a version 1 API resistance into its stored form. It returns false for the null
+// resistance, which is not stored.
+func ResistanceFromApi(apiResistance picolApiV1.Resistance) (Resistance, bool) {
+	if apiResistance.IsNull() {
+		return Resistance{}, false
+	}
+
+	return Resistance{
+		Id:             apiResistance.Id,
+		Source:         apiResistance.Source,
+		Code:           apiResistance.Code,
+		MethodOfAction: apiResistance.MethodOfAction,
+	}, true
+}
+
+// ResistanceToApi converts a stored resistance into a version 1 API resistance.
+func ResistanceToApi(resistance Resistance) picolApiV1.Resistance {
+	return picolApiV1.Resistance{
+		Id:             resistance.Id,
+		Source:         resistance.Source,
+		Code:           resistance.Code,
+		MethodOfAction: resistance.MethodOfAction,
+	}
+}
+
+// ResistanceSourceFromApi converts a resistance source into its stored form, with its website as a canonical URL.
+func ResistanceSourceFromApi(apiSource picolApiV1.ResistanceSource) (ResistanceSource, error) {
+	url, err := apiSource.URL()
+	if err != nil {
+		return ResistanceSource{}, err
+	}
+
+	return ResistanceSource{
+		Source:      apiSource.Source,
+		Name:        apiSource.Name,
+		Url:         url,
+		Version:     apiSource.Version,
+		PublishedAt: apiSource.PublishedAt,
+		Codes:       apiSource.Codes,
+	}, nil
+}
+
+// ResistanceSourceToApi converts a stored resistance source into a resistance source.
+func ResistanceSourceToApi(source ResistanceSource) picolApiV1.ResistanceSource {
+	return picolApiV1.ResistanceSource{
+		Source:      source.Source,
+		Name:        source.Name,
+		Url:         source.Url,
+		Version:     source.Version,
+		PublishedAt: source.PublishedAt,
+		Codes:       source.Codes,
+	}
+}
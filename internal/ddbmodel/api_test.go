@@ -0,0 +1,66 @@
+package ddbmodel
+
+import (
+	"reflect"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+var (
+	frac3 = picolApiV1.Resistance{Id: 3, Source: "FRAC", Code: "3", MethodOfAction: "DMI fungicides"}
+	frac7 = picolApiV1.Resistance{Id: 7, Source: "FRAC", Code: "7", MethodOfAction: "SDHI fungicides"}
+)
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func TestIngredientFromApi(t *testing.T) {
+	tests := []struct {
+		name       string
+		ingredient picolApiV1.Ingredient
+		want       Ingredient
+	}{
+		{"one group", picolApiV1.Ingredient{Id: 101, Resistance: frac3}, Ingredient{Id: 101, ResistanceId: intPointer(3), ResistanceIds: []int{3}}},
+		{"several groups", picolApiV1.Ingredient{Id: 101, Resistance: frac7, Resistances: []picolApiV1.Resistance{frac7, frac3}}, Ingredient{Id: 101, ResistanceId: intPointer(7), ResistanceIds: []int{7, 3}}},
+		{"null resistance", picolApiV1.Ingredient{Id: 101, Resistance: picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}}, Ingredient{Id: 101}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IngredientFromApi(test.ingredient); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIngredientToApi(t *testing.T) {
+	resistances := map[int]Resistance{}
+	for _, apiResistance := range []picolApiV1.Resistance{frac3, frac7} {
+		resistance, _ := ResistanceFromApi(apiResistance)
+		resistances[resistance.Id] = resistance
+	}
+	null := picolApiV1.Resistance{Id: picolApiV1.NullResistanceId}
+
+	tests := []struct {
+		name       string
+		ingredient Ingredient
+		want       picolApiV1.Ingredient
+	}{
+		{"one group", Ingredient{Id: 101, ResistanceId: intPointer(3), ResistanceIds: []int{3}}, picolApiV1.Ingredient{Id: 101, Resistance: frac3}},
+		{"several groups", Ingredient{Id: 101, ResistanceId: intPointer(7), ResistanceIds: []int{3, 7}}, picolApiV1.Ingredient{Id: 101, Resistance: frac7, Resistances: []picolApiV1.Resistance{frac7, frac3}}},
+		{"stored before ResistanceIds", Ingredient{Id: 101, ResistanceId: intPointer(3)}, picolApiV1.Ingredient{Id: 101, Resistance: frac3}},
+		{"no groups", Ingredient{Id: 101}, picolApiV1.Ingredient{Id: 101, Resistance: null}},
+		{"unknown group", Ingredient{Id: 101, ResistanceId: intPointer(99), ResistanceIds: []int{99, 3}}, picolApiV1.Ingredient{Id: 101, Resistance: frac3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IngredientToApi(test.ingredient, resistances); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Code generated by enumgen from datasets/applications-2023-10-17.json; DO NOT EDIT.
+
+package ddbmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type Application int8
+
+const (
+	ApplicationAerial        Application = 1
+	ApplicationGround        Application = 2
+	ApplicationIrrigation    Application = 3
+	ApplicationSeedTreatment Application = 4
+	ApplicationPlantDip      Application = 5
+)
+
+// AllApplications returns all known applications, ordered by id.
+func AllApplications() []Application {
+	return []Application{
+		ApplicationAerial,
+		ApplicationGround,
+		ApplicationIrrigation,
+		ApplicationSeedTreatment,
+		ApplicationPlantDip,
+	}
+}
+
+// IsValid reports whether a is a known application.
+func (a Application) IsValid() bool {
+	switch a {
+	case ApplicationAerial, ApplicationGround, ApplicationIrrigation, ApplicationSeedTreatment, ApplicationPlantDip:
+		return true
+	}
+	return false
+}
+
+// Code returns the single-character code for the application, or 0 if it is unknown.
+func (a Application) Code() byte {
+	switch a {
+	case ApplicationAerial:
+		return 'A'
+	case ApplicationGround:
+		return 'G'
+	case ApplicationIrrigation:
+		return 'I'
+	case ApplicationSeedTreatment:
+		return 'S'
+	case ApplicationPlantDip:
+		return 'P'
+	}
+	return 0
+}
+
+// Name returns the PICOL name for the application, or an empty string if it is unknown.
+func (a Application) Name() string {
+	switch a {
+	case ApplicationAerial:
+		return "AERIAL"
+	case ApplicationGround:
+		return "GROUND"
+	case ApplicationIrrigation:
+		return "IRRIGATION"
+	case ApplicationSeedTreatment:
+		return "SEED TREATMENT"
+	case ApplicationPlantDip:
+		return "PLANT DIP"
+	}
+	return ""
+}
+
+// String returns a human-readable name for the application.
+func (a Application) String() string {
+	switch a {
+	case ApplicationAerial:
+		return "Aerial"
+	case ApplicationGround:
+		return "Ground"
+	case ApplicationIrrigation:
+		return "Irrigation"
+	case ApplicationSeedTreatment:
+		return "Seed Treatment"
+	case ApplicationPlantDip:
+		return "Plant Dip"
+	}
+	return "Application(" + strconv.Itoa(int(a)) + ")"
+}
+
+// ParseApplicationCode returns the application with the given single-character code.
+func ParseApplicationCode(code string) (Application, error) {
+	switch code {
+	case "A":
+		return ApplicationAerial, nil
+	case "G":
+		return ApplicationGround, nil
+	case "I":
+		return ApplicationIrrigation, nil
+	case "S":
+		return ApplicationSeedTreatment, nil
+	case "P":
+		return ApplicationPlantDip, nil
+	}
+	return 0, fmt.Errorf("unknown application code: %q", code)
+}
+
+// ParseApplicationName returns the application with the given name, ignoring case.
+func ParseApplicationName(name string) (Application, error) {
+	for _, a := range AllApplications() {
+		if strings.EqualFold(name, a.Name()) {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown application name: %q", name)
+}
+
+// MarshalDynamoDBAttributeValue stores the application as its numeric id.
+func (a Application) MarshalDynamoDBAttributeValue() (ddbTypes.AttributeValue, error) {
+	if !a.IsValid() {
+		return nil, fmt.Errorf("unknown application: %d", int(a))
+	}
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(int(a))}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue reads the application from its numeric id.
+func (a *Application) UnmarshalDynamoDBAttributeValue(av ddbTypes.AttributeValue) error {
+	n, ok := av.(*ddbTypes.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("expected a number attribute value for application, got %T", av)
+	}
+
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return err
+	}
+
+	value := Application(id)
+	if id != int(value) || !value.IsValid() {
+		return fmt.Errorf("unknown application: %s", n.Value)
+	}
+
+	*a = value
+	return nil
+}
+
+// MarshalJSON encodes the application as its PICOL name.
+func (a Application) MarshalJSON() ([]byte, error) {
+	if !a.IsValid() {
+		return nil, fmt.Errorf("unknown application: %d", int(a))
+	}
+	return json.Marshal(a.Name())
+}
+
+// UnmarshalJSON decodes the application from its PICOL name or code.
+func (a *Application) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err != nil {
+		return err
+	}
+
+	value, err := ParseApplicationName(text)
+	if err != nil {
+		value, err = ParseApplicationCode(text)
+	}
+	if err != nil {
+		return err
+	}
+
+	*a = value
+	return nil
+}
@@ -1,8 +1,10 @@
 package ddbmodel
 
 type Crop struct {
-	Id    int
-	Code  string
-	Name  string
-	Notes string `dynamodbav:",omitempty"`
+	Id       int
+	Code     string
+	Name     string
+	SortName string `dynamodbav:",omitempty"`
+	Notes    string `dynamodbav:",omitempty"`
+	Retired  bool   `dynamodbav:",omitempty"`
 }
@@ -0,0 +1,447 @@
+// Command enumgen generates the ddbmodel enumeration types from the PICOL datasets.
+//
+// It is run via go generate from the ddbmodel package:
+//
+//	go generate ./internal/ddbmodel
+//
+// For each enumeration, the newest <entity>-YYYY-MM-DD.json file in the datasets directory is read and a
+// <type>_gen.go file is written containing the constants, lookup and parse functions, and DynamoDB and JSON
+// marshalers.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// enumSpec describes an enumeration to generate.
+type enumSpec struct {
+	// Dataset entity name, e.g. "applications".
+	Entity string
+
+	// Go type name, e.g. "Application".
+	Type string
+
+	// Receiver name for methods.
+	Receiver string
+
+	// Output filename.
+	File string
+
+	// Whether values have a single-character code.
+	HasCode bool
+
+	// Overrides for identifiers and display strings that cannot be derived from the name, keyed by dataset name.
+	Overrides map[string]override
+}
+
+// override replaces the derived identifier suffix and display string for a value.
+type override struct {
+	Ident  string
+	String string
+}
+
+var enumSpecs = []enumSpec{
+	{
+		Entity:   "applications",
+		Type:     "Application",
+		Receiver: "a",
+		File:     "application_gen.go",
+		HasCode:  true,
+	},
+	{
+		Entity:   "intended-users",
+		Type:     "IntendedUser",
+		Receiver: "iu",
+		File:     "intended_user_gen.go",
+		HasCode:  true,
+	},
+	{
+		Entity:   "signal-words",
+		Type:     "SignalWord",
+		Receiver: "sw",
+		File:     "signal_word_gen.go",
+		HasCode:  true,
+		Overrides: map[string]override{
+			"NO SIGNAL WORD GIVEN": {Ident: "None", String: "None"},
+		},
+	},
+	{
+		Entity:   "states",
+		Type:     "State",
+		Receiver: "s",
+		File:     "state_gen.go",
+	},
+}
+
+// record is a dataset record. States have no code.
+type record struct {
+	Id   int
+	Name string
+	Code string
+}
+
+// enumValue is a single value passed to the template.
+type enumValue struct {
+	Ident  string
+	Id     int
+	Code   string
+	Name   string
+	String string
+}
+
+var datasetFilenameRegexp = regexp.MustCompile(`^([a-z]+(?:-[a-z]+)*)-(\d{4}-\d{2}-\d{2})\.json$`)
+
+func main() {
+	datasetsDir := flag.String("datasets", "../../datasets", "Directory containing the dataset files.")
+	outputDir := flag.String("output", ".", "Directory to write the generated files to.")
+	flag.Parse()
+
+	for _, spec := range enumSpecs {
+		err := generate(spec, *datasetsDir, *outputDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "enumgen: %s: %s\n", spec.Type, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func generate(spec enumSpec, datasetsDir string, outputDir string) error {
+	filename, err := latestDatasetFile(datasetsDir, spec.Entity)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Error   bool
+		Message string
+		Data    []record
+	}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return fmt.Errorf("error decoding %s: %w", filename, err)
+	}
+
+	if response.Error {
+		return fmt.Errorf("%s is an error response: %s", filename, response.Message)
+	}
+
+	if len(response.Data) == 0 {
+		return fmt.Errorf("%s contains no values", filename)
+	}
+
+	sort.Slice(response.Data, func(i, j int) bool { return response.Data[i].Id < response.Data[j].Id })
+
+	values := make([]enumValue, 0, len(response.Data))
+	seenIds := map[int]bool{}
+	seenIdents := map[string]bool{}
+	seenCodes := map[string]bool{}
+	for _, r := range response.Data {
+		if r.Id < 1 || r.Id > 127 {
+			return fmt.Errorf("id %d for %q does not fit in an int8", r.Id, r.Name)
+		}
+
+		if seenIds[r.Id] {
+			return fmt.Errorf("duplicate id %d", r.Id)
+		}
+		seenIds[r.Id] = true
+
+		if spec.HasCode {
+			if len(r.Code) != 1 {
+				return fmt.Errorf("code %q for %q is not a single character", r.Code, r.Name)
+			}
+			if seenCodes[r.Code] {
+				return fmt.Errorf("duplicate code %q", r.Code)
+			}
+			seenCodes[r.Code] = true
+		}
+
+		v := enumValue{
+			Ident:  spec.Type + identifier(r.Name),
+			Id:     r.Id,
+			Code:   r.Code,
+			Name:   r.Name,
+			String: titleCase(r.Name),
+		}
+
+		if o, found := spec.Overrides[r.Name]; found {
+			v.Ident = spec.Type + o.Ident
+			v.String = o.String
+		}
+
+		if seenIdents[v.Ident] {
+			return fmt.Errorf("duplicate identifier %s", v.Ident)
+		}
+		seenIdents[v.Ident] = true
+
+		values = append(values, v)
+	}
+
+	var buf bytes.Buffer
+	err = enumTemplate.Execute(&buf, map[string]any{
+		"Spec":    spec,
+		"Source":  filepath.ToSlash(filepath.Join("datasets", filepath.Base(filename))),
+		"Values":  values,
+		"Plural":  plural(spec.Type),
+		"Display": strings.ToLower(displayName(spec.Type)),
+	})
+	if err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("error formatting generated code: %w\n%s", err, buf.String())
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, spec.File), src, 0o644)
+}
+
+// latestDatasetFile returns the path of the newest dataset file for the given entity in dir.
+func latestDatasetFile(dir string, entity string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	latestName := ""
+	latestDate := ""
+	for _, entry := range entries {
+		match := datasetFilenameRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil || match[1] != entity {
+			continue
+		}
+
+		if match[2] > latestDate {
+			latestName = entry.Name()
+			latestDate = match[2]
+		}
+	}
+
+	if latestName == "" {
+		return "", fmt.Errorf("no %s dataset found in %s", entity, dir)
+	}
+
+	return filepath.Join(dir, latestName), nil
+}
+
+// identifier converts a dataset name such as "DANGER/POISON" into a Go identifier suffix such as "DangerPoison".
+func identifier(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		b.WriteString(titleCase(word))
+	}
+	return b.String()
+}
+
+// titleCase converts a dataset name such as "PLANT DIP" into a display string such as "Plant Dip".
+func titleCase(name string) string {
+	var b strings.Builder
+	startOfWord := true
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if startOfWord {
+				b.WriteRune(unicode.ToUpper(r))
+			} else {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			startOfWord = false
+		} else {
+			b.WriteRune(r)
+			startOfWord = true
+		}
+	}
+	return b.String()
+}
+
+// displayName splits a type name such as "SignalWord" into words such as "Signal Word".
+func displayName(typeName string) string {
+	var b strings.Builder
+	for i, r := range typeName {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func plural(typeName string) string {
+	return typeName + "s"
+}
+
+var enumTemplate = template.Must(template.New("enum").Parse(`// Code generated by enumgen from {{.Source}}; DO NOT EDIT.
+
+package ddbmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+{{- $spec := .Spec }}
+{{- $r := .Spec.Receiver }}
+{{- $t := .Spec.Type }}
+
+type {{$t}} int8
+
+const (
+{{- range .Values}}
+	{{.Ident}} {{$t}} = {{.Id}}
+{{- end}}
+)
+
+// All{{.Plural}} returns all known {{.Display}}s, ordered by id.
+func All{{.Plural}}() []{{$t}} {
+	return []{{$t}}{
+{{- range .Values}}
+		{{.Ident}},
+{{- end}}
+	}
+}
+
+// IsValid reports whether {{$r}} is a known {{.Display}}.
+func ({{$r}} {{$t}}) IsValid() bool {
+	switch {{$r}} {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Ident}}{{end}}:
+		return true
+	}
+	return false
+}
+{{- if $spec.HasCode}}
+
+// Code returns the single-character code for the {{.Display}}, or 0 if it is unknown.
+func ({{$r}} {{$t}}) Code() byte {
+	switch {{$r}} {
+{{- range .Values}}
+	case {{.Ident}}:
+		return '{{.Code}}'
+{{- end}}
+	}
+	return 0
+}
+{{- end}}
+
+// Name returns the PICOL name for the {{.Display}}, or an empty string if it is unknown.
+func ({{$r}} {{$t}}) Name() string {
+	switch {{$r}} {
+{{- range .Values}}
+	case {{.Ident}}:
+		return {{printf "%q" .Name}}
+{{- end}}
+	}
+	return ""
+}
+
+// String returns a human-readable name for the {{.Display}}.
+func ({{$r}} {{$t}}) String() string {
+	switch {{$r}} {
+{{- range .Values}}
+	case {{.Ident}}:
+		return {{printf "%q" .String}}
+{{- end}}
+	}
+	return "{{$t}}(" + strconv.Itoa(int({{$r}})) + ")"
+}
+{{- if $spec.HasCode}}
+
+// Parse{{$t}}Code returns the {{.Display}} with the given single-character code.
+func Parse{{$t}}Code(code string) ({{$t}}, error) {
+	switch code {
+{{- range .Values}}
+	case {{printf "%q" .Code}}:
+		return {{.Ident}}, nil
+{{- end}}
+	}
+	return 0, fmt.Errorf("unknown {{.Display}} code: %q", code)
+}
+{{- end}}
+
+// Parse{{$t}}Name returns the {{.Display}} with the given name, ignoring case.
+func Parse{{$t}}Name(name string) ({{$t}}, error) {
+	for _, {{$r}} := range All{{.Plural}}() {
+		if strings.EqualFold(name, {{$r}}.Name()) {
+			return {{$r}}, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown {{.Display}} name: %q", name)
+}
+
+// MarshalDynamoDBAttributeValue stores the {{.Display}} as its numeric id.
+func ({{$r}} {{$t}}) MarshalDynamoDBAttributeValue() (ddbTypes.AttributeValue, error) {
+	if !{{$r}}.IsValid() {
+		return nil, fmt.Errorf("unknown {{.Display}}: %d", int({{$r}}))
+	}
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(int({{$r}}))}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue reads the {{.Display}} from its numeric id.
+func ({{$r}} *{{$t}}) UnmarshalDynamoDBAttributeValue(av ddbTypes.AttributeValue) error {
+	n, ok := av.(*ddbTypes.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("expected a number attribute value for {{.Display}}, got %T", av)
+	}
+
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return err
+	}
+
+	value := {{$t}}(id)
+	if id != int(value) || !value.IsValid() {
+		return fmt.Errorf("unknown {{.Display}}: %s", n.Value)
+	}
+
+	*{{$r}} = value
+	return nil
+}
+
+// MarshalJSON encodes the {{.Display}} as its PICOL name.
+func ({{$r}} {{$t}}) MarshalJSON() ([]byte, error) {
+	if !{{$r}}.IsValid() {
+		return nil, fmt.Errorf("unknown {{.Display}}: %d", int({{$r}}))
+	}
+	return json.Marshal({{$r}}.Name())
+}
+
+// UnmarshalJSON decodes the {{.Display}} from its PICOL name{{if $spec.HasCode}} or code{{end}}.
+func ({{$r}} *{{$t}}) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err != nil {
+		return err
+	}
+
+	value, err := Parse{{$t}}Name(text)
+{{- if $spec.HasCode}}
+	if err != nil {
+		value, err = Parse{{$t}}Code(text)
+	}
+{{- end}}
+	if err != nil {
+		return err
+	}
+
+	*{{$r}} = value
+	return nil
+}
+`))
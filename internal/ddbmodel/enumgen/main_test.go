@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedFilesAreUpToDate regenerates each enumeration from the datasets and compares it with the file checked
+// in to the ddbmodel package, so that neither the datasets nor the generated files change without the other.
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	outputDir := t.TempDir()
+
+	for _, spec := range enumSpecs {
+		t.Run(spec.Type, func(t *testing.T) {
+			err := generate(spec, "../../../datasets", outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(outputDir, spec.File))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("..", spec.File))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("internal/ddbmodel/%s is out of date; run go generate ./internal/ddbmodel", spec.File)
+			}
+		})
+	}
+}
+
+func TestGenerateRejectsInvalidDatasets(t *testing.T) {
+	spec := enumSpec{Entity: "signal-words", Type: "SignalWord", Receiver: "sw", File: "signal_word_gen.go", HasCode: true}
+
+	tests := []struct {
+		name    string
+		dataset string
+		want    string
+	}{
+		{"error response", `{"Error": true, "Message": "boom", "Data": []}`, "is an error response: boom"},
+		{"no values", `{"Data": []}`, "contains no values"},
+		{"id too large", `{"Data": [{"Id": 128, "Name": "DANGER", "Code": "D"}]}`, "does not fit in an int8"},
+		{"duplicate id", `{"Data": [{"Id": 1, "Name": "DANGER", "Code": "D"}, {"Id": 1, "Name": "WARNING", "Code": "W"}]}`, "duplicate id 1"},
+		{"long code", `{"Data": [{"Id": 1, "Name": "DANGER", "Code": "DA"}]}`, "is not a single character"},
+		{"duplicate code", `{"Data": [{"Id": 1, "Name": "DANGER", "Code": "D"}, {"Id": 2, "Name": "DEADLY", "Code": "D"}]}`, `duplicate code "D"`},
+		{"duplicate identifier", `{"Data": [{"Id": 1, "Name": "DANGER/POISON", "Code": "D"}, {"Id": 2, "Name": "DANGER POISON", "Code": "P"}]}`, "duplicate identifier SignalWordDangerPoison"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			datasetsDir := t.TempDir()
+			err := os.WriteFile(filepath.Join(datasetsDir, "signal-words-2024-01-01.json"), []byte(test.dataset), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = generate(spec, datasetsDir, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLatestDatasetFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"states-2023-10-17.json", "states-2024-02-01.json", "signal-words-2025-01-01.json", "states-2024-02-01-rejects.json"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(`{}`), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := latestDatasetFile(dir, "states")
+	if err != nil || got != filepath.Join(dir, "states-2024-02-01.json") {
+		t.Errorf("got %q and error %v, want states-2024-02-01.json", got, err)
+	}
+
+	if _, err := latestDatasetFile(dir, "applications"); err == nil {
+		t.Errorf("found an applications dataset in %s", dir)
+	}
+}
+
+func TestNames(t *testing.T) {
+	if got := identifier("DANGER/POISON"); got != "DangerPoison" {
+		t.Errorf("identifier(\"DANGER/POISON\") = %q", got)
+	}
+	if got := titleCase("PLANT DIP"); got != "Plant Dip" {
+		t.Errorf("titleCase(\"PLANT DIP\") = %q", got)
+	}
+	if got := displayName("SignalWord"); got != "Signal Word" {
+		t.Errorf("displayName(\"SignalWord\") = %q", got)
+	}
+}
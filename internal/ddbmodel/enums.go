@@ -0,0 +1,6 @@
+package ddbmodel
+
+// The enumeration types Application, IntendedUser, SignalWord and State are generated from the newest files in the
+// datasets directory. Adding or changing a value is a data change: update the dataset and regenerate.
+
+//go:generate go run ./enumgen -datasets ../../datasets -output .
@@ -2,9 +2,30 @@ package ddbmodel
 
 type Ingredient struct {
 	Id             int
-	ResistanceId   *int `dynamodbav:",omitempty"`
+	ResistanceId   *int  `dynamodbav:",omitempty"`
+	ResistanceIds  []int `dynamodbav:",numberset,omitempty"`
 	Name           string
+	SortName       string `dynamodbav:",omitempty"`
 	Code           string
 	Notes          string `dynamodbav:",omitempty"`
 	ManagementCode string `dynamodbav:",omitempty"`
+	Retired        bool   `dynamodbav:",omitempty"`
+}
+
+// AllResistanceIds returns the ids of every resistance group of a stored ingredient, with the primary one first.
+// Ingredients stored before ResistanceIds existed only have ResistanceId.
+func (i Ingredient) AllResistanceIds() []int {
+	var ids []int
+	seen := map[int]bool{}
+	if i.ResistanceId != nil {
+		ids = append(ids, *i.ResistanceId)
+		seen[*i.ResistanceId] = true
+	}
+	for _, id := range i.ResistanceIds {
+		if !seen[id] {
+			ids = append(ids, id)
+			seen[id] = true
+		}
+	}
+	return ids
 }
@@ -0,0 +1,147 @@
+// Code generated by enumgen from datasets/intended-users-2023-10-17.json; DO NOT EDIT.
+
+package ddbmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type IntendedUser int8
+
+const (
+	IntendedUserCommercial IntendedUser = 1
+	IntendedUserHome       IntendedUser = 2
+)
+
+// AllIntendedUsers returns all known intended users, ordered by id.
+func AllIntendedUsers() []IntendedUser {
+	return []IntendedUser{
+		IntendedUserCommercial,
+		IntendedUserHome,
+	}
+}
+
+// IsValid reports whether iu is a known intended user.
+func (iu IntendedUser) IsValid() bool {
+	switch iu {
+	case IntendedUserCommercial, IntendedUserHome:
+		return true
+	}
+	return false
+}
+
+// Code returns the single-character code for the intended user, or 0 if it is unknown.
+func (iu IntendedUser) Code() byte {
+	switch iu {
+	case IntendedUserCommercial:
+		return 'C'
+	case IntendedUserHome:
+		return 'H'
+	}
+	return 0
+}
+
+// Name returns the PICOL name for the intended user, or an empty string if it is unknown.
+func (iu IntendedUser) Name() string {
+	switch iu {
+	case IntendedUserCommercial:
+		return "COMMERCIAL"
+	case IntendedUserHome:
+		return "HOME"
+	}
+	return ""
+}
+
+// String returns a human-readable name for the intended user.
+func (iu IntendedUser) String() string {
+	switch iu {
+	case IntendedUserCommercial:
+		return "Commercial"
+	case IntendedUserHome:
+		return "Home"
+	}
+	return "IntendedUser(" + strconv.Itoa(int(iu)) + ")"
+}
+
+// ParseIntendedUserCode returns the intended user with the given single-character code.
+func ParseIntendedUserCode(code string) (IntendedUser, error) {
+	switch code {
+	case "C":
+		return IntendedUserCommercial, nil
+	case "H":
+		return IntendedUserHome, nil
+	}
+	return 0, fmt.Errorf("unknown intended user code: %q", code)
+}
+
+// ParseIntendedUserName returns the intended user with the given name, ignoring case.
+func ParseIntendedUserName(name string) (IntendedUser, error) {
+	for _, iu := range AllIntendedUsers() {
+		if strings.EqualFold(name, iu.Name()) {
+			return iu, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown intended user name: %q", name)
+}
+
+// MarshalDynamoDBAttributeValue stores the intended user as its numeric id.
+func (iu IntendedUser) MarshalDynamoDBAttributeValue() (ddbTypes.AttributeValue, error) {
+	if !iu.IsValid() {
+		return nil, fmt.Errorf("unknown intended user: %d", int(iu))
+	}
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(int(iu))}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue reads the intended user from its numeric id.
+func (iu *IntendedUser) UnmarshalDynamoDBAttributeValue(av ddbTypes.AttributeValue) error {
+	n, ok := av.(*ddbTypes.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("expected a number attribute value for intended user, got %T", av)
+	}
+
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return err
+	}
+
+	value := IntendedUser(id)
+	if id != int(value) || !value.IsValid() {
+		return fmt.Errorf("unknown intended user: %s", n.Value)
+	}
+
+	*iu = value
+	return nil
+}
+
+// MarshalJSON encodes the intended user as its PICOL name.
+func (iu IntendedUser) MarshalJSON() ([]byte, error) {
+	if !iu.IsValid() {
+		return nil, fmt.Errorf("unknown intended user: %d", int(iu))
+	}
+	return json.Marshal(iu.Name())
+}
+
+// UnmarshalJSON decodes the intended user from its PICOL name or code.
+func (iu *IntendedUser) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err != nil {
+		return err
+	}
+
+	value, err := ParseIntendedUserName(text)
+	if err != nil {
+		value, err = ParseIntendedUserCode(text)
+	}
+	if err != nil {
+		return err
+	}
+
+	*iu = value
+	return nil
+}
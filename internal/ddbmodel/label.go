@@ -0,0 +1,39 @@
+package ddbmodel
+
+type Label struct {
+	Id                     int
+	Name                   string
+	SortName               string `dynamodbav:",omitempty"`
+	EpaNumber              string
+	IntendedUserId         int
+	IngredientIds          []int `dynamodbav:",numberset,omitempty"`
+	PesticideTypeIds       []int `dynamodbav:",numberset,omitempty"`
+	CropIds                []int `dynamodbav:",numberset,omitempty"`
+	PestIds                []int `dynamodbav:",numberset,omitempty"`
+	RegistrantId           int
+	Sln                    string             `dynamodbav:",omitempty"`
+	SlnName                string             `dynamodbav:",omitempty"`
+	SlnExpiration          string             `dynamodbav:",omitempty"` // YYYY-MM-DD
+	StateRecords           []LabelStateRecord `dynamodbav:",omitempty"`
+	Supplemental           string             `dynamodbav:",omitempty"`
+	SupplementalName       string             `dynamodbav:",omitempty"`
+	SupplementalExpiration string             `dynamodbav:",omitempty"` // YYYY-MM-DD
+	Formulation            string             `dynamodbav:",omitempty"`
+	SignalWordId           *int               `dynamodbav:",omitempty"`
+	Usage                  string             `dynamodbav:",omitempty"`
+	Organic                *bool              `dynamodbav:",omitempty"`
+	EsaNotice              *bool              `dynamodbav:",omitempty"`
+	Section18              string             `dynamodbav:",omitempty"`
+	Retired                bool               `dynamodbav:",omitempty"`
+}
+
+// LabelStateRecord is a state registration record stored as a nested item on a Label.
+type LabelStateRecord struct {
+	Id       int
+	StateId  int
+	AgencyId string `dynamodbav:",omitempty"`
+	Version  string `dynamodbav:",omitempty"`
+	Year     int
+	I502     bool
+	Essb6206 bool
+}
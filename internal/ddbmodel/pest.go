@@ -1,8 +1,10 @@
 package ddbmodel
 
 type Pest struct {
-	Id    int
-	Name  string
-	Code  string
-	Notes string `dynamodbav:",omitempty"`
+	Id       int
+	Name     string
+	SortName string `dynamodbav:",omitempty"`
+	Code     string
+	Notes    string `dynamodbav:",omitempty"`
+	Retired  bool   `dynamodbav:",omitempty"`
 }
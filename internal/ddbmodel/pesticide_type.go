@@ -0,0 +1,9 @@
+package ddbmodel
+
+type PesticideType struct {
+	Id       int
+	Name     string
+	SortName string `dynamodbav:",omitempty"`
+	Code     string
+	Retired  bool `dynamodbav:",omitempty"`
+}
@@ -1,7 +1,11 @@
 package ddbmodel
 
 type Registrant struct {
-	Id   int
-	Name string
-	Url  string `dynamodbav:",omitempty"`
+	Id          int
+	Name        string
+	SortName    string `dynamodbav:",omitempty"`
+	Url         string `dynamodbav:",omitempty"`
+	OriginalUrl string `dynamodbav:",omitempty"`
+	Reserved    bool   `dynamodbav:",omitempty"`
+	Retired     bool   `dynamodbav:",omitempty"`
 }
@@ -6,6 +6,7 @@ type Resistance struct {
 	Code           string
 	MethodOfAction string
 	Ingredients    []int `dynamodbav:",numberset"`
+	Retired        bool  `dynamodbav:",omitempty"`
 
 	// Rid is not accessible
 }
@@ -0,0 +1,11 @@
+package ddbmodel
+
+type ResistanceSource struct {
+	Source      string
+	Name        string
+	Url         string   `dynamodbav:",omitempty"`
+	Version     string   `dynamodbav:",omitempty"`
+	PublishedAt string   `dynamodbav:",omitempty"`
+	Codes       []string `dynamodbav:",stringset,omitempty"`
+	Retired     bool     `dynamodbav:",omitempty"`
+}
@@ -0,0 +1,177 @@
+// Code generated by enumgen from datasets/signal-words-2023-10-17.json; DO NOT EDIT.
+
+package ddbmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type SignalWord int8
+
+const (
+	SignalWordCaution      SignalWord = 1
+	SignalWordDanger       SignalWord = 2
+	SignalWordDangerPoison SignalWord = 3
+	SignalWordWarning      SignalWord = 4
+	SignalWordNone         SignalWord = 5
+)
+
+// AllSignalWords returns all known signal words, ordered by id.
+func AllSignalWords() []SignalWord {
+	return []SignalWord{
+		SignalWordCaution,
+		SignalWordDanger,
+		SignalWordDangerPoison,
+		SignalWordWarning,
+		SignalWordNone,
+	}
+}
+
+// IsValid reports whether sw is a known signal word.
+func (sw SignalWord) IsValid() bool {
+	switch sw {
+	case SignalWordCaution, SignalWordDanger, SignalWordDangerPoison, SignalWordWarning, SignalWordNone:
+		return true
+	}
+	return false
+}
+
+// Code returns the single-character code for the signal word, or 0 if it is unknown.
+func (sw SignalWord) Code() byte {
+	switch sw {
+	case SignalWordCaution:
+		return 'C'
+	case SignalWordDanger:
+		return 'D'
+	case SignalWordDangerPoison:
+		return 'T'
+	case SignalWordWarning:
+		return 'W'
+	case SignalWordNone:
+		return 'N'
+	}
+	return 0
+}
+
+// Name returns the PICOL name for the signal word, or an empty string if it is unknown.
+func (sw SignalWord) Name() string {
+	switch sw {
+	case SignalWordCaution:
+		return "CAUTION"
+	case SignalWordDanger:
+		return "DANGER"
+	case SignalWordDangerPoison:
+		return "DANGER/POISON"
+	case SignalWordWarning:
+		return "WARNING"
+	case SignalWordNone:
+		return "NO SIGNAL WORD GIVEN"
+	}
+	return ""
+}
+
+// String returns a human-readable name for the signal word.
+func (sw SignalWord) String() string {
+	switch sw {
+	case SignalWordCaution:
+		return "Caution"
+	case SignalWordDanger:
+		return "Danger"
+	case SignalWordDangerPoison:
+		return "Danger/Poison"
+	case SignalWordWarning:
+		return "Warning"
+	case SignalWordNone:
+		return "None"
+	}
+	return "SignalWord(" + strconv.Itoa(int(sw)) + ")"
+}
+
+// ParseSignalWordCode returns the signal word with the given single-character code.
+func ParseSignalWordCode(code string) (SignalWord, error) {
+	switch code {
+	case "C":
+		return SignalWordCaution, nil
+	case "D":
+		return SignalWordDanger, nil
+	case "T":
+		return SignalWordDangerPoison, nil
+	case "W":
+		return SignalWordWarning, nil
+	case "N":
+		return SignalWordNone, nil
+	}
+	return 0, fmt.Errorf("unknown signal word code: %q", code)
+}
+
+// ParseSignalWordName returns the signal word with the given name, ignoring case.
+func ParseSignalWordName(name string) (SignalWord, error) {
+	for _, sw := range AllSignalWords() {
+		if strings.EqualFold(name, sw.Name()) {
+			return sw, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signal word name: %q", name)
+}
+
+// MarshalDynamoDBAttributeValue stores the signal word as its numeric id.
+func (sw SignalWord) MarshalDynamoDBAttributeValue() (ddbTypes.AttributeValue, error) {
+	if !sw.IsValid() {
+		return nil, fmt.Errorf("unknown signal word: %d", int(sw))
+	}
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(int(sw))}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue reads the signal word from its numeric id.
+func (sw *SignalWord) UnmarshalDynamoDBAttributeValue(av ddbTypes.AttributeValue) error {
+	n, ok := av.(*ddbTypes.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("expected a number attribute value for signal word, got %T", av)
+	}
+
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return err
+	}
+
+	value := SignalWord(id)
+	if id != int(value) || !value.IsValid() {
+		return fmt.Errorf("unknown signal word: %s", n.Value)
+	}
+
+	*sw = value
+	return nil
+}
+
+// MarshalJSON encodes the signal word as its PICOL name.
+func (sw SignalWord) MarshalJSON() ([]byte, error) {
+	if !sw.IsValid() {
+		return nil, fmt.Errorf("unknown signal word: %d", int(sw))
+	}
+	return json.Marshal(sw.Name())
+}
+
+// UnmarshalJSON decodes the signal word from its PICOL name or code.
+func (sw *SignalWord) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err != nil {
+		return err
+	}
+
+	value, err := ParseSignalWordName(text)
+	if err != nil {
+		value, err = ParseSignalWordCode(text)
+	}
+	if err != nil {
+		return err
+	}
+
+	*sw = value
+	return nil
+}
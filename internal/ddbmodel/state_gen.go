@@ -0,0 +1,122 @@
+// Code generated by enumgen from datasets/states-2023-10-17.json; DO NOT EDIT.
+
+package ddbmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type State int8
+
+const (
+	StateWashington State = 1
+	StateOregon     State = 2
+)
+
+// AllStates returns all known states, ordered by id.
+func AllStates() []State {
+	return []State{
+		StateWashington,
+		StateOregon,
+	}
+}
+
+// IsValid reports whether s is a known state.
+func (s State) IsValid() bool {
+	switch s {
+	case StateWashington, StateOregon:
+		return true
+	}
+	return false
+}
+
+// Name returns the PICOL name for the state, or an empty string if it is unknown.
+func (s State) Name() string {
+	switch s {
+	case StateWashington:
+		return "Washington"
+	case StateOregon:
+		return "Oregon"
+	}
+	return ""
+}
+
+// String returns a human-readable name for the state.
+func (s State) String() string {
+	switch s {
+	case StateWashington:
+		return "Washington"
+	case StateOregon:
+		return "Oregon"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseStateName returns the state with the given name, ignoring case.
+func ParseStateName(name string) (State, error) {
+	for _, s := range AllStates() {
+		if strings.EqualFold(name, s.Name()) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state name: %q", name)
+}
+
+// MarshalDynamoDBAttributeValue stores the state as its numeric id.
+func (s State) MarshalDynamoDBAttributeValue() (ddbTypes.AttributeValue, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("unknown state: %d", int(s))
+	}
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(int(s))}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue reads the state from its numeric id.
+func (s *State) UnmarshalDynamoDBAttributeValue(av ddbTypes.AttributeValue) error {
+	n, ok := av.(*ddbTypes.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("expected a number attribute value for state, got %T", av)
+	}
+
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return err
+	}
+
+	value := State(id)
+	if id != int(value) || !value.IsValid() {
+		return fmt.Errorf("unknown state: %s", n.Value)
+	}
+
+	*s = value
+	return nil
+}
+
+// MarshalJSON encodes the state as its PICOL name.
+func (s State) MarshalJSON() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("unknown state: %d", int(s))
+	}
+	return json.Marshal(s.Name())
+}
+
+// UnmarshalJSON decodes the state from its PICOL name.
+func (s *State) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err != nil {
+		return err
+	}
+
+	value, err := ParseStateName(text)
+	if err != nil {
+		return err
+	}
+
+	*s = value
+	return nil
+}
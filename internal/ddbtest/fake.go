@@ -0,0 +1,441 @@
+// Package ddbtest provides an in-memory fake of the DynamoDB API for tests.
+package ddbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Fake is an in-memory DynamoDB endpoint that supports the requests PICOL makes: BatchGetItem,
+// BatchWriteItem, PutItem, UpdateItem, TransactWriteItems and Scan. Items are kept in their JSON wire form, e.g.
+// {"Id": {"N": "5"}}. Update and condition expressions are only parsed as far as the importers use them.
+type Fake struct {
+	mu     sync.Mutex
+	tables map[string]map[string]map[string]any
+
+	// Writes to these items fail, by table name and key, e.g. "TResistances/72".
+	FailWrites map[string]bool
+
+	// Functions run once before the next request of an operation, e.g. "TransactWriteItems", to simulate another
+	// writer. They run with the fake locked, so they change items through Item rather than SetItem.
+	Before map[string]func()
+}
+
+// apiError is an error response of the fake.
+type apiError struct {
+	Type    string
+	Message string
+
+	// For TransactionCanceledException, the reason for each action.
+	Reasons []string
+}
+
+func (e *apiError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+var errConditionalCheckFailed = &apiError{Type: "ConditionalCheckFailedException", Message: "The conditional request failed"}
+
+// New starts a fake DynamoDB endpoint that is stopped when the test ends, and returns it with an AWS configuration
+// that points clients at it.
+func New(t *testing.T) (*Fake, aws.Config) {
+	fake := &Fake{tables: map[string]map[string]map[string]any{}, FailWrites: map[string]bool{}, Before: map[string]func(){}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config := aws.Config{
+		Region: "us-west-2",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		EndpointResolverWithOptions: aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{URL: server.URL}, nil
+		}),
+		HTTPClient: server.Client(),
+	}
+
+	return fake, config
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+
+	var request map[string]any
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &request)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	if before := f.Before[operation]; before != nil {
+		delete(f.Before, operation)
+		before()
+	}
+	response, err := f.handle(operation, request)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if err != nil {
+		ferr := err.(*apiError)
+		body := map[string]any{"__type": "com.amazonaws.dynamodb.v20120810#" + ferr.Type, "message": ferr.Message}
+		if ferr.Reasons != nil {
+			reasons := make([]any, len(ferr.Reasons))
+			for i, code := range ferr.Reasons {
+				reasons[i] = map[string]any{"Code": code}
+			}
+			body["CancellationReasons"] = reasons
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (f *Fake) handle(operation string, request map[string]any) (map[string]any, error) {
+	switch operation {
+	case "BatchGetItem":
+		responses := map[string]any{}
+		for tableName, keysAndAttributes := range request["RequestItems"].(map[string]any) {
+			found := []any{}
+			for _, key := range keysAndAttributes.(map[string]any)["Keys"].([]any) {
+				if item := f.Item(tableName, itemKey(tableName, key.(map[string]any))); item != nil {
+					found = append(found, item)
+				}
+			}
+			responses[tableName] = found
+		}
+		return map[string]any{"Responses": responses}, nil
+
+	case "BatchWriteItem":
+		for tableName, requests := range request["RequestItems"].(map[string]any) {
+			for _, wr := range requests.([]any) {
+				if put, ok := wr.(map[string]any)["PutRequest"]; ok {
+					err := f.put(map[string]any{"TableName": tableName, "Item": put.(map[string]any)["Item"]})
+					if err != nil {
+						return nil, err
+					}
+				}
+				if del, ok := wr.(map[string]any)["DeleteRequest"]; ok {
+					delete(f.table(tableName), itemKey(tableName, del.(map[string]any)["Key"].(map[string]any)))
+				}
+			}
+		}
+		return map[string]any{}, nil
+
+	case "PutItem":
+		return map[string]any{}, f.put(request)
+
+	case "UpdateItem":
+		return map[string]any{}, f.update(request)
+
+	case "TransactWriteItems":
+		return map[string]any{}, f.transactWrite(request)
+
+	case "Scan":
+		tableName := request["TableName"].(string)
+		items := []any{}
+		for _, key := range f.Keys(tableName) {
+			items = append(items, f.Item(tableName, key))
+		}
+		response := map[string]any{"Count": len(items), "ScannedCount": len(items)}
+		if request["Select"] != "COUNT" {
+			response["Items"] = items
+		}
+		return response, nil
+	}
+
+	return nil, &apiError{Type: "UnknownOperationException", Message: operation}
+}
+
+// SetItem stores an item, given in its JSON wire form.
+func (f *Fake) SetItem(tableName string, item string) {
+	var value map[string]any
+	err := json.Unmarshal([]byte(item), &value)
+	if err != nil {
+		panic(err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.table(tableName)[itemKey(tableName, value)] = value
+}
+
+// Item returns the item with the given key, or nil if there is none.
+func (f *Fake) Item(tableName string, key string) map[string]any {
+	return f.tables[tableName][key]
+}
+
+// Keys returns the keys of the items in a table, in order.
+func (f *Fake) Keys(tableName string) []string {
+	keys := make([]string, 0, len(f.tables[tableName]))
+	for key := range f.tables[tableName] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *Fake) table(tableName string) map[string]map[string]any {
+	if f.tables[tableName] == nil {
+		f.tables[tableName] = map[string]map[string]any{}
+	}
+	return f.tables[tableName]
+}
+
+// itemKey returns the key of an item or key as a string, e.g. "72".
+func itemKey(tableName string, item map[string]any) string {
+	keyName := "Id"
+	switch {
+	case strings.HasSuffix(tableName, "Sequences"):
+		keyName = "SequenceName"
+	case strings.HasSuffix(tableName, "ResistanceSources"):
+		keyName = "Source"
+	}
+
+	for _, value := range item[keyName].(map[string]any) {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+func (f *Fake) put(request map[string]any) error {
+	tableName := request["TableName"].(string)
+	item := request["Item"].(map[string]any)
+	key := itemKey(tableName, item)
+
+	if f.FailWrites[tableName+"/"+key] {
+		return &apiError{Type: "ValidationException", Message: "injected failure"}
+	}
+	if !evalCondition(request, f.Item(tableName, key)) {
+		return errConditionalCheckFailed
+	}
+
+	f.table(tableName)[key] = item
+	return nil
+}
+
+var clauseRegexp = regexp.MustCompile(`\b(SET|REMOVE|ADD|DELETE)\b`)
+
+// update applies an update expression made of SET, REMOVE, ADD and DELETE clauses. ADD and DELETE only support
+// number sets.
+func (f *Fake) update(request map[string]any) error {
+	tableName := request["TableName"].(string)
+	keyItem := request["Key"].(map[string]any)
+	key := itemKey(tableName, keyItem)
+
+	if f.FailWrites[tableName+"/"+key] {
+		return &apiError{Type: "ValidationException", Message: "injected failure"}
+	}
+
+	current := f.Item(tableName, key)
+	if !evalCondition(request, current) {
+		return errConditionalCheckFailed
+	}
+
+	item := map[string]any{}
+	for name, value := range current {
+		item[name] = value
+	}
+	for name, value := range keyItem {
+		item[name] = value
+	}
+
+	expression := request["UpdateExpression"].(string)
+	clauses := clauseRegexp.FindAllStringIndex(expression, -1)
+	for i, clause := range clauses {
+		end := len(expression)
+		if i+1 < len(clauses) {
+			end = clauses[i+1][0]
+		}
+		keyword := expression[clause[0]:clause[1]]
+
+		for _, action := range strings.Split(expression[clause[1]:end], ",") {
+			action = strings.TrimSpace(action)
+			switch keyword {
+			case "SET":
+				name, value, _ := strings.Cut(action, "=")
+				item[attributeName(request, name)] = attributeValue(request, value)
+			case "REMOVE":
+				delete(item, attributeName(request, action))
+			case "ADD", "DELETE":
+				fields := strings.Fields(action)
+				name := attributeName(request, fields[0])
+				members := map[string]bool{}
+				if set, ok := item[name].(map[string]any); ok {
+					for _, n := range set["NS"].([]any) {
+						members[n.(string)] = true
+					}
+				}
+				for _, n := range attributeValue(request, fields[1]).(map[string]any)["NS"].([]any) {
+					members[n.(string)] = keyword == "ADD"
+				}
+
+				var set []any
+				for n, member := range members {
+					if member {
+						set = append(set, n)
+					}
+				}
+				sort.Slice(set, func(i, j int) bool { return set[i].(string) < set[j].(string) })
+				if len(set) == 0 {
+					delete(item, name)
+				} else {
+					item[name] = map[string]any{"NS": set}
+				}
+			}
+		}
+	}
+
+	f.table(tableName)[key] = item
+	return nil
+}
+
+// transactWrite applies the Put, Update and ConditionCheck actions of a transaction, or none of them if any fails.
+func (f *Fake) transactWrite(request map[string]any) error {
+	snapshot := map[string]map[string]map[string]any{}
+	for tableName, table := range f.tables {
+		snapshot[tableName] = map[string]map[string]any{}
+		for key, item := range table {
+			snapshot[tableName][key] = item
+		}
+	}
+
+	var reasons []string
+	failed := false
+	for _, action := range request["TransactItems"].([]any) {
+		var err error
+		if put, ok := action.(map[string]any)["Put"]; ok {
+			err = f.put(put.(map[string]any))
+		} else if update, ok := action.(map[string]any)["Update"]; ok {
+			err = f.update(update.(map[string]any))
+		} else if check, ok := action.(map[string]any)["ConditionCheck"]; ok {
+			tableName := check.(map[string]any)["TableName"].(string)
+			item := f.Item(tableName, itemKey(tableName, check.(map[string]any)["Key"].(map[string]any)))
+			if !evalCondition(check.(map[string]any), item) {
+				err = errConditionalCheckFailed
+			}
+		} else {
+			err = &apiError{Type: "ValidationException", Message: "unsupported transaction action"}
+		}
+
+		switch {
+		case err == nil:
+			reasons = append(reasons, "None")
+		case err == errConditionalCheckFailed:
+			reasons = append(reasons, "ConditionalCheckFailed")
+			failed = true
+		default:
+			reasons = append(reasons, "ValidationError")
+			failed = true
+		}
+	}
+
+	if failed {
+		f.tables = snapshot
+		return &apiError{Type: "TransactionCanceledException", Message: "Transaction cancelled", Reasons: reasons}
+	}
+	return nil
+}
+
+func attributeName(request map[string]any, name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "#") {
+		return request["ExpressionAttributeNames"].(map[string]any)[name].(string)
+	}
+	return name
+}
+
+func attributeValue(request map[string]any, placeholder string) any {
+	return request["ExpressionAttributeValues"].(map[string]any)[strings.TrimSpace(placeholder)]
+}
+
+var comparisonRegexp = regexp.MustCompile(`^(\S+)\s*(=|<)\s*(\S+)$`)
+
+// evalCondition evaluates a condition expression made of attribute_exists, attribute_not_exists, = and <, joined by
+// AND and OR without parentheses. = compares numbers and number sets by value, < only numbers.
+func evalCondition(request map[string]any, item map[string]any) bool {
+	condition, _ := request["ConditionExpression"].(string)
+	if condition == "" {
+		return true
+	}
+
+	for _, conjunct := range strings.Split(condition, " AND ") {
+		if !evalDisjunction(request, item, conjunct) {
+			return false
+		}
+	}
+	return true
+}
+
+func evalDisjunction(request map[string]any, item map[string]any, condition string) bool {
+	for _, term := range strings.Split(condition, " OR ") {
+		term = strings.TrimSpace(term)
+		if name, ok := strings.CutPrefix(term, "attribute_exists("); ok {
+			if _, found := item[attributeName(request, strings.TrimSuffix(name, ")"))]; found {
+				return true
+			}
+			continue
+		}
+		if name, ok := strings.CutPrefix(term, "attribute_not_exists("); ok {
+			if _, found := item[attributeName(request, strings.TrimSuffix(name, ")"))]; !found {
+				return true
+			}
+			continue
+		}
+
+		match := comparisonRegexp.FindStringSubmatch(term)
+		if match == nil {
+			panic("unsupported condition: " + term)
+		}
+		left, found := item[attributeName(request, match[1])].(map[string]any)
+		if !found {
+			continue
+		}
+		right := attributeValue(request, match[3]).(map[string]any)
+		if match[2] == "=" && numbers(left) != nil && reflect.DeepEqual(numbers(left), numbers(right)) {
+			return true
+		}
+		l, _ := strconv.ParseFloat(fmt.Sprint(left["N"]), 64)
+		r, _ := strconv.ParseFloat(fmt.Sprint(right["N"]), 64)
+		if match[2] == "<" && l < r {
+			return true
+		}
+	}
+
+	return false
+}
+
+// numbers returns the sorted values of a number or number set, or nil for other values.
+func numbers(value map[string]any) []float64 {
+	var numbers []float64
+	if n, ok := value["N"]; ok {
+		f, _ := strconv.ParseFloat(fmt.Sprint(n), 64)
+		numbers = append(numbers, f)
+	}
+	if ns, ok := value["NS"].([]any); ok {
+		for _, n := range ns {
+			f, _ := strconv.ParseFloat(fmt.Sprint(n), 64)
+			numbers = append(numbers, f)
+		}
+		sort.Float64s(numbers)
+	}
+	return numbers
+}
@@ -51,3 +51,32 @@ func NS1(n int64) *ddbTypes.AttributeValueMemberNS {
 	s[0] = strconv.FormatInt(n, 10)
 	return &ddbTypes.AttributeValueMemberNS{Value: s}
 }
+
+// SS creates a new DynamoDB string set attribute value member from a list of strings.
+func SS(ss []string) *ddbTypes.AttributeValueMemberSS {
+	return &ddbTypes.AttributeValueMemberSS{Value: ss}
+}
+
+// AsS returns the value of a DynamoDB string attribute value member, or "" if av is not one.
+func AsS(av ddbTypes.AttributeValue) string {
+	if s, ok := av.(*ddbTypes.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
+
+// AsSS returns the value of a DynamoDB string set attribute value member, or nil if av is not one.
+func AsSS(av ddbTypes.AttributeValue) []string {
+	if ss, ok := av.(*ddbTypes.AttributeValueMemberSS); ok {
+		return ss.Value
+	}
+	return nil
+}
+
+// AsBOOL returns the value of a DynamoDB boolean attribute value member, or false if av is not one.
+func AsBOOL(av ddbTypes.AttributeValue) bool {
+	if b, ok := av.(*ddbTypes.AttributeValueMemberBOOL); ok {
+		return b.Value
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package ddbutil
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// MaxRequestAttempts is the number of times a throttled or partially processed request is attempted before giving up.
+	MaxRequestAttempts = 8
+
+	// baseRetryDelay and maxRetryDelay bound the exponential backoff between attempts.
+	baseRetryDelay = 50 * time.Millisecond
+	maxRetryDelay  = 5 * time.Second
+)
+
+// WithRetry calls op, retrying with exponential backoff while it fails with a throttling error. The SDK already
+// retries throttled requests a few times; this covers sustained throttling during large imports and scans.
+func WithRetry(ctx context.Context, op func() error) error {
+	return WithRetryIf(ctx, IsThrottlingError, op)
+}
+
+// WithRetryIf calls op, retrying with exponential backoff while it fails with an error for which retryable returns
+// true.
+func WithRetryIf(ctx context.Context, retryable func(err error) bool, op func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !retryable(err) || attempt == MaxRequestAttempts {
+			return err
+		}
+
+		err = SleepContext(ctx, RetryDelay(attempt))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// IsThrottlingError reports whether err indicates that DynamoDB throttled the request.
+func IsThrottlingError(err error) bool {
+	var ptee *ddbTypes.ProvisionedThroughputExceededException
+	if errors.As(err, &ptee) {
+		return true
+	}
+
+	var rle *ddbTypes.RequestLimitExceeded
+	if errors.As(err, &rle) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "ThrottlingException"
+}
+
+// RetryDelay returns a randomized exponential backoff delay for the given attempt number.
+func RetryDelay(attempt int) time.Duration {
+	delay := baseRetryDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// SleepContext sleeps for the given duration or until the context is done.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,45 @@
+package ddbutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ScanAttributes returns the given attributes of every item in a table, retrying pages that DynamoDB throttles.
+func ScanAttributes(ctx context.Context, client *dynamodb.Client, tableName string, attributes []string) ([]map[string]ddbTypes.AttributeValue, error) {
+	names := make(map[string]string, len(attributes))
+	projection := make([]string, len(attributes))
+	for i, attribute := range attributes {
+		placeholder := fmt.Sprintf("#A%d", i)
+		names[placeholder] = attribute
+		projection[i] = placeholder
+	}
+
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                aws.String(tableName),
+		ProjectionExpression:     aws.String(strings.Join(projection, ", ")),
+		ExpressionAttributeNames: names,
+		ConsistentRead:           aws.Bool(true),
+	})
+
+	var items []map[string]ddbTypes.AttributeValue
+	for paginator.HasMorePages() {
+		var page *dynamodb.ScanOutput
+		err := WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+	}
+
+	return items, nil
+}
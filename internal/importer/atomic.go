@@ -0,0 +1,261 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// transactMaxItems is the maximum number of actions DynamoDB accepts in a single TransactWriteItems request.
+const transactMaxItems = 100
+
+// clientRequestTokenLength is the maximum length of a TransactWriteItems client request token.
+const clientRequestTokenLength = 36
+
+// transactGroup is the set of writes for one record that are applied in a single transaction: the record's own item,
+// or a check that it is unchanged, and its related updates.
+type transactGroup struct {
+	// Key of the record's item, e.g. "Id=305".
+	Key string
+
+	// Index of the record in the input.
+	Record int
+
+	// Diff status of the record's item if the group writes it, otherwise empty.
+	Status string
+
+	Items []ddbTypes.TransactWriteItem
+
+	// ClientRequestToken makes retries of the transaction idempotent.
+	ClientRequestToken string
+}
+
+// transactUpdate converts an UpdateItem request into a transaction action.
+func transactUpdate(uii *dynamodb.UpdateItemInput) ddbTypes.TransactWriteItem {
+	return ddbTypes.TransactWriteItem{Update: &ddbTypes.Update{
+		TableName:                 uii.TableName,
+		Key:                       uii.Key,
+		UpdateExpression:          uii.UpdateExpression,
+		ConditionExpression:       uii.ConditionExpression,
+		ExpressionAttributeNames:  uii.ExpressionAttributeNames,
+		ExpressionAttributeValues: uii.ExpressionAttributeValues,
+	}}
+}
+
+// clientRequestToken derives a transaction token from the table, record key and the transaction's writes, including
+// their conditions on the items as they were read. Running the same import again within DynamoDB's ten-minute
+// idempotency window therefore replays the same transactions, while a record whose writes differ from the earlier
+// run's, e.g. because its item is now unchanged, gets a new token instead of failing with
+// IdempotentParameterMismatchException.
+func clientRequestToken(tableName string, key string, items []ddbTypes.TransactWriteItem) string {
+	h := sha256.New()
+	for _, part := range []string{tableName, key} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	json.NewEncoder(h).Encode(transactItemsInterface(items))
+	return hex.EncodeToString(h.Sum(nil))[:clientRequestTokenLength]
+}
+
+// transactItemsInterface converts transaction actions into plain Go values whose JSON encoding is the same whenever
+// the actions are. Attribute values keep their type, e.g. {"N": "5"}, and sets are sorted.
+func transactItemsInterface(items []ddbTypes.TransactWriteItem) []any {
+	action := func(kind string, tableName *string, item map[string]ddbTypes.AttributeValue, update *string, condition *string, names map[string]string, values map[string]ddbTypes.AttributeValue) map[string]any {
+		return map[string]any{
+			"Action":    kind,
+			"TableName": aws.ToString(tableName),
+			"Item":      typedAttributeValues(item),
+			"Update":    aws.ToString(update),
+			"Condition": aws.ToString(condition),
+			"Names":     names,
+			"Values":    typedAttributeValues(values),
+		}
+	}
+
+	actions := make([]any, len(items))
+	for i, item := range items {
+		switch {
+		case item.Put != nil:
+			p := item.Put
+			actions[i] = action("Put", p.TableName, p.Item, nil, p.ConditionExpression, p.ExpressionAttributeNames, p.ExpressionAttributeValues)
+		case item.Update != nil:
+			u := item.Update
+			actions[i] = action("Update", u.TableName, u.Key, u.UpdateExpression, u.ConditionExpression, u.ExpressionAttributeNames, u.ExpressionAttributeValues)
+		case item.Delete != nil:
+			d := item.Delete
+			actions[i] = action("Delete", d.TableName, d.Key, nil, d.ConditionExpression, d.ExpressionAttributeNames, d.ExpressionAttributeValues)
+		case item.ConditionCheck != nil:
+			cc := item.ConditionCheck
+			actions[i] = action("ConditionCheck", cc.TableName, cc.Key, nil, cc.ConditionExpression, cc.ExpressionAttributeNames, cc.ExpressionAttributeValues)
+		}
+	}
+	return actions
+}
+
+func typedAttributeValues(m map[string]ddbTypes.AttributeValue) map[string]any {
+	typed := make(map[string]any, len(m))
+	for name, av := range m {
+		typed[name] = typedAttributeValue(av)
+	}
+	return typed
+}
+
+// typedAttributeValue is like attributeValueInterface, but keeps the type of the value and does not normalize numbers.
+func typedAttributeValue(av ddbTypes.AttributeValue) any {
+	sorted := func(values []string) []string {
+		values = append([]string(nil), values...)
+		sort.Strings(values)
+		return values
+	}
+
+	switch v := av.(type) {
+	case *ddbTypes.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}
+	case *ddbTypes.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}
+	case *ddbTypes.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}
+	case *ddbTypes.AttributeValueMemberNULL:
+		return map[string]any{"NULL": v.Value}
+	case *ddbTypes.AttributeValueMemberB:
+		return map[string]any{"B": v.Value}
+	case *ddbTypes.AttributeValueMemberSS:
+		return map[string]any{"SS": sorted(v.Value)}
+	case *ddbTypes.AttributeValueMemberNS:
+		return map[string]any{"NS": sorted(v.Value)}
+	case *ddbTypes.AttributeValueMemberBS:
+		bs := make([]string, len(v.Value))
+		for i, b := range v.Value {
+			bs[i] = hex.EncodeToString(b)
+		}
+		return map[string]any{"BS": sorted(bs)}
+	case *ddbTypes.AttributeValueMemberL:
+		l := make([]any, len(v.Value))
+		for i, element := range v.Value {
+			l[i] = typedAttributeValue(element)
+		}
+		return map[string]any{"L": l}
+	case *ddbTypes.AttributeValueMemberM:
+		return map[string]any{"M": typedAttributeValues(v.Value)}
+	}
+
+	return fmt.Sprintf("%v", av)
+}
+
+// transact writes a group with a TransactWriteItems request. Transactions that are cancelled by throttling or by a
+// conflicting transaction are retried with the same client request token.
+func (tw *tableWriter) transact(ctx context.Context, group transactGroup) error {
+	if len(group.Items) > transactMaxItems {
+		return fmt.Errorf("%d writes exceed the transaction limit of %d", len(group.Items), transactMaxItems)
+	}
+
+	input := dynamodb.TransactWriteItemsInput{
+		TransactItems:      group.Items,
+		ClientRequestToken: aws.String(group.ClientRequestToken),
+	}
+	err := ddbutil.WithRetryIf(ctx, isTransientTransactionError, func() error {
+		_, err := tw.client.TransactWriteItems(ctx, &input)
+		return err
+	})
+	return withCancellationReasons(err)
+}
+
+// expectCondition returns a condition that holds while the given attributes of an item still have the values they
+// have in current, the item as it was read, or while the item does not exist if current is nil. Placeholders are
+// named #Expected0, :Expected0 and so on.
+func (tw *tableWriter) expectCondition(current map[string]ddbTypes.AttributeValue, attributes []string) (string, map[string]string, map[string]ddbTypes.AttributeValue) {
+	names := map[string]string{}
+	values := map[string]ddbTypes.AttributeValue{}
+
+	if current == nil {
+		names["#Expected0"] = tw.keyNames[0]
+		return "attribute_not_exists(#Expected0)", names, nil
+	}
+
+	terms := []string{"attribute_exists(#Expected0)"}
+	names["#Expected0"] = tw.keyNames[0]
+	for i, attribute := range attributes {
+		name, value := fmt.Sprintf("#Expected%d", i+1), fmt.Sprintf(":Expected%d", i+1)
+		names[name] = attribute
+		if av, found := current[attribute]; found {
+			values[value] = av
+			terms = append(terms, fmt.Sprintf("%s = %s", name, value))
+		} else {
+			terms = append(terms, fmt.Sprintf("attribute_not_exists(%s)", name))
+		}
+	}
+
+	if len(values) == 0 {
+		values = nil
+	}
+	return strings.Join(terms, " AND "), names, values
+}
+
+// isConditionFailure reports whether a transaction was cancelled because the condition of its action at index i
+// failed.
+func isConditionFailure(err error, i int) bool {
+	var tce *ddbTypes.TransactionCanceledException
+	if !errors.As(err, &tce) || i >= len(tce.CancellationReasons) {
+		return false
+	}
+	return aws.ToString(tce.CancellationReasons[i].Code) == "ConditionalCheckFailed"
+}
+
+// isTransientTransactionError reports whether a transaction failed for a reason that may go away on its own:
+// throttling, a conflicting transaction, or an earlier attempt with the same token still being in progress.
+func isTransientTransactionError(err error) bool {
+	if ddbutil.IsThrottlingError(err) {
+		return true
+	}
+
+	var tipe *ddbTypes.TransactionInProgressException
+	if errors.As(err, &tipe) {
+		return true
+	}
+
+	var tce *ddbTypes.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return false
+	}
+
+	transient := false
+	for _, reason := range tce.CancellationReasons {
+		switch aws.ToString(reason.Code) {
+		case "", "None":
+		case "ThrottlingError", "TransactionConflict", "ProvisionedThroughputExceeded":
+			transient = true
+		default:
+			return false
+		}
+	}
+	return transient
+}
+
+// withCancellationReasons adds the cancellation reason of each action to a cancelled transaction's error, e.g.
+// "[None, ConditionalCheckFailed]", so that reports show which write failed.
+func withCancellationReasons(err error) error {
+	var tce *ddbTypes.TransactionCanceledException
+	if !errors.As(err, &tce) || len(tce.CancellationReasons) == 0 {
+		return err
+	}
+
+	reasons := make([]string, len(tce.CancellationReasons))
+	for i, reason := range tce.CancellationReasons {
+		reasons[i] = aws.ToString(reason.Code)
+		if reasons[i] == "" {
+			reasons[i] = "None"
+		}
+	}
+
+	return fmt.Errorf("%w [%s]", err, strings.Join(reasons, ", "))
+}
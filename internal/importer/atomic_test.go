@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// ingredientWrites returns the transaction of an ingredient that moves to a resistance whose ingredients are ids.
+func ingredientWrites(name string, ids ...int64) []ddbTypes.TransactWriteItem {
+	return []ddbTypes.TransactWriteItem{
+		{Put: &ddbTypes.Put{
+			TableName: aws.String("TIngredients"),
+			Item:      map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(305), "Name": ddbutil.S(name)},
+		}},
+		{Update: &ddbTypes.Update{
+			TableName:                 aws.String("TResistances"),
+			Key:                       map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(72)},
+			UpdateExpression:          aws.String("ADD Ingredients :ids"),
+			ConditionExpression:       aws.String("Ingredients = :old"),
+			ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{":ids": ddbutil.NS1(305), ":old": ddbutil.NS(ids)},
+		}},
+	}
+}
+
+func TestClientRequestToken(t *testing.T) {
+	token := clientRequestToken("TIngredients", "Id=305", ingredientWrites("ABAMECTIN", 101, 102))
+	if len(token) != clientRequestTokenLength {
+		t.Errorf("got token %q of length %d, want %d", token, len(token), clientRequestTokenLength)
+	}
+
+	// The same writes, even with sets in another order, replay the same transaction.
+	for _, again := range []string{
+		clientRequestToken("TIngredients", "Id=305", ingredientWrites("ABAMECTIN", 101, 102)),
+		clientRequestToken("TIngredients", "Id=305", ingredientWrites("ABAMECTIN", 102, 101)),
+	} {
+		if again != token {
+			t.Errorf("got token %q for the same writes, want %q", again, token)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		tableName string
+		key       string
+		items     []ddbTypes.TransactWriteItem
+	}{
+		{"other table", "TPests", "Id=305", ingredientWrites("ABAMECTIN", 101, 102)},
+		{"other record", "TIngredients", "Id=306", ingredientWrites("ABAMECTIN", 101, 102)},
+		{"changed item", "TIngredients", "Id=305", ingredientWrites("ABAMECTIN B1", 101, 102)},
+		{"changed condition", "TIngredients", "Id=305", ingredientWrites("ABAMECTIN", 101)},
+		{"fewer writes", "TIngredients", "Id=305", ingredientWrites("ABAMECTIN", 101, 102)[:1]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := clientRequestToken(test.tableName, test.key, test.items); got == token {
+				t.Errorf("got the same token %q for different writes", got)
+			}
+		})
+	}
+}
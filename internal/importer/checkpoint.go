@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkpointInterval is how often import progress is saved while writing.
+const checkpointInterval = 2 * time.Second
+
+// checkpoint is the progress of an import, saved to a local state file so that an interrupted import can be resumed
+// with -resume.
+type checkpoint struct {
+	Subcommand  string
+	Table       string
+	InputSHA256 string
+	UpdatedAt   time.Time
+
+	// Keys of the records that were completely written, including their related updates, e.g. "Id=305".
+	Committed []string
+}
+
+// checkpointer tracks committed records and saves them to the state file. It is safe for concurrent use.
+type checkpointer struct {
+	mu        sync.Mutex
+	filename  string
+	state     checkpoint
+	committed map[string]bool
+	dirty     bool
+}
+
+// DefaultStateDir returns the default directory for import state files.
+func DefaultStateDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "picol", "imports")
+}
+
+// checkpointFilename returns the state file for importing the input with the given checksum into a table.
+func checkpointFilename(dir string, table string, inputSHA256 string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.json", table, inputSHA256))
+}
+
+// loadCheckpoint reads a state file. It returns nil if the file does not exist.
+func loadCheckpoint(filename string) (*checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state checkpoint
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return &state, nil
+}
+
+// newCheckpointer creates a checkpointer that saves to filename, starting from the given state. If filename is empty,
+// nothing is saved until setInput names the file.
+func newCheckpointer(filename string, state checkpoint) *checkpointer {
+	return &checkpointer{filename: filename, state: state, committed: keySet(state.Committed)}
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setInput sets the state file and checksum of the input once the checksum is known.
+func (c *checkpointer) setInput(filename string, inputSHA256 string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.filename = filename
+	c.state.InputSHA256 = inputSHA256
+}
+
+// commit records that the record with the given key is completely written.
+func (c *checkpointer) commit(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.committed[key] {
+		c.committed[key] = true
+		c.dirty = true
+	}
+}
+
+// isCommitted reports whether the record with the given key was completely written.
+func (c *checkpointer) isCommitted(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.committed[key]
+}
+
+// save writes the state file if anything was committed since the last save. The file is replaced atomically so that
+// an interruption never leaves a truncated state file behind.
+func (c *checkpointer) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty || c.filename == "" {
+		return nil
+	}
+
+	c.state.UpdatedAt = time.Now().UTC()
+	c.state.Committed = sortedKeys(c.committed)
+
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.filename), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.filename), filepath.Base(c.filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), c.filename)
+	if err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// autosave saves the state file periodically until the returned function is called.
+func (c *checkpointer) autosave(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// A failed save is retried on the next tick and reported by the final save.
+				_ = c.save()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// finish removes the state file of a complete import, or saves it so that an incomplete one can be resumed.
+func (c *checkpointer) finish(complete bool) {
+	if complete {
+		err := c.remove()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing checkpoint: %s\n", err)
+		}
+		return
+	}
+
+	err := c.save()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving checkpoint: %s\n", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.committed) > 0 && c.filename != "" {
+		fmt.Fprintf(os.Stderr, "Progress saved to %s; run the same command with -resume to continue.\n", c.filename)
+	}
+}
+
+// remove deletes the state file.
+func (c *checkpointer) remove() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.filename == "" {
+		return nil
+	}
+	err := os.Remove(c.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointerSavesAndRemoves(t *testing.T) {
+	filename := checkpointFilename(filepath.Join(t.TempDir(), "imports"), "TCrops", "abc123")
+
+	cp := newCheckpointer("", checkpoint{Subcommand: "import-crops", Table: "TCrops"})
+	cp.commit("Id=2")
+	cp.commit("Id=1")
+	cp.commit("Id=2")
+
+	// Nothing is saved before the input is known.
+	err := cp.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatalf("saved %s before its input was set", filename)
+	}
+
+	cp.setInput(filename, "abc123")
+	cp.finish(false)
+
+	state, err := loadCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil {
+		t.Fatalf("incomplete import removed %s", filename)
+	}
+	want := checkpoint{Subcommand: "import-crops", Table: "TCrops", InputSHA256: "abc123", UpdatedAt: state.UpdatedAt, Committed: []string{"Id=1", "Id=2"}}
+	if !reflect.DeepEqual(*state, want) {
+		t.Errorf("got checkpoint %+v, want %+v", *state, want)
+	}
+
+	// A resumed import starts from the saved keys.
+	resumed := newCheckpointer(filename, *state)
+	if !resumed.isCommitted("Id=1") || resumed.isCommitted("Id=3") {
+		t.Errorf("resumed checkpointer has the wrong keys")
+	}
+
+	resumed.finish(true)
+	state, err = loadCheckpoint(filename)
+	if err != nil || state != nil {
+		t.Errorf("got checkpoint %+v and error %v after a complete import, want none", state, err)
+	}
+}
+
+func TestRunResumes(t *testing.T) {
+	ti := newTestImport(t)
+	ti.fake.FailWrites["TCrops/3"] = true
+	filename := ti.writeFile("crops.json",
+		testCrop{Id: 1, Name: "APPLE"},
+		testCrop{Id: 2, Name: "PEAR"},
+		testCrop{Id: 3, Name: "PLUM"})
+
+	// Crops are created one at a time, in order, so crops 1 and 2 are written before crop 3 fails.
+	rc, _ := ti.run(testDescriptor(), []string{"-concurrency", "1"}, filename)
+	if rc == 0 {
+		t.Fatalf("import succeeded despite a failed write")
+	}
+
+	matches, err := filepath.Glob(filepath.Join(ti.dir, "TCrops-*.json"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("got checkpoints %q, want one", matches)
+	}
+	state, err := loadCheckpoint(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Id=1", "Id=2"}; !reflect.DeepEqual(state.Committed, want) {
+		t.Fatalf("got committed crops %q, want %q", state.Committed, want)
+	}
+
+	// Starting over fails on the crops that were written.
+	delete(ti.fake.FailWrites, "TCrops/3")
+	if rc, _ := ti.run(testDescriptor(), nil, filename); rc == 0 {
+		t.Errorf("import without -resume accepted crops that were already written")
+	}
+
+	// Committed crops are not compared or written again, so this change survives the resumed import.
+	ti.fake.SetItem("TCrops", storedCrop(1, "CHANGED"))
+
+	rc, report := ti.run(testDescriptor(), []string{"-resume"}, filename)
+	if rc != 0 {
+		t.Fatalf("resumed import exited with %d: %s", rc, report.Error)
+	}
+	if report.Skipped != 2 || report.Created != 1 {
+		t.Errorf("got %d skipped and %d created, want 2 and 1", report.Skipped, report.Created)
+	}
+	if name := ti.fake.Item("TCrops", "1")["Name"]; !reflect.DeepEqual(name, map[string]any{"S": "CHANGED"}) {
+		t.Errorf("resumed import rewrote committed crop 1")
+	}
+	if keys := ti.fake.Keys("TCrops"); len(keys) != 3 {
+		t.Errorf("got crops %q, want 1 to 3", keys)
+	}
+	if _, err := os.Stat(matches[0]); !os.IsNotExist(err) {
+		t.Errorf("complete import left its checkpoint %s", matches[0])
+	}
+}
@@ -0,0 +1,423 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// batchGetMaxKeys is the maximum number of keys DynamoDB accepts in a single BatchGetItem request.
+const batchGetMaxKeys = 100
+
+const (
+	diffStatusCreated   = "created"
+	diffStatusChanged   = "changed"
+	diffStatusUnchanged = "unchanged"
+	diffStatusConflict  = "conflict"
+	diffStatusDeleted   = "deleted"
+	diffStatusRetired   = "retired"
+)
+
+// diffOptions controls how an import is compared against the current table contents.
+type diffOptions struct {
+	// Whether the import may update existing items. If not, any existing item is a conflict.
+	AllowUpdate bool
+
+	// Whether existing items that are identical to the new item are unchanged rather than conflicts when AllowUpdate
+	// is false.
+	AcceptUnchanged bool
+
+	// Whether the import replaces whole items. If so, attributes of existing items that are not in the new item are
+	// reported as removed; otherwise they are ignored.
+	Replace bool
+
+	// Attributes that an update removes when they are absent from the new item. Only used if Replace is false.
+	Removable []string
+}
+
+// recordDiff describes how a single imported record differs from the current item.
+type recordDiff struct {
+	Key     string        `json:"key"`
+	Status  string        `json:"status"`
+	Changes []fieldChange `json:"changes,omitempty"`
+
+	// The item in the table before the import, or nil if there is none.
+	current map[string]ddbTypes.AttributeValue
+}
+
+// fieldChange is a single attribute change. Old or New is nil when the attribute is absent.
+type fieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// diffSummary counts records by status.
+type diffSummary struct {
+	Created   int `json:"created"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+	Conflict  int `json:"conflict"`
+	Deleted   int `json:"deleted"`
+	Retired   int `json:"retired"`
+}
+
+// tableDiff is the comparison of a chunk of items with a table. The JSON dry-run output of a whole import has the same
+// fields, with the summary after the records.
+type tableDiff struct {
+	Table   string       `json:"table"`
+	Summary diffSummary  `json:"summary"`
+	Records []recordDiff `json:"records"`
+}
+
+// ValidDiffFormat reports whether format is a supported -diff-format value.
+func ValidDiffFormat(format string) bool {
+	return format == "text" || format == "json"
+}
+
+// compareItems reads the current version of each item and works out how the import would change it. The records of
+// the result correspond to items, in order. Nothing is written to the table.
+func (tw *tableWriter) compareItems(ctx context.Context, items []map[string]ddbTypes.AttributeValue, options diffOptions) (tableDiff, error) {
+	current, err := tw.getItems(ctx, items)
+	if err != nil {
+		return tableDiff{}, err
+	}
+
+	td := tableDiff{Table: tw.tableName, Records: make([]recordDiff, 0, len(items))}
+	for _, item := range items {
+		key := tw.keyString(item)
+		old, exists := current[key]
+		rd := recordDiff{Key: key, current: old}
+
+		switch {
+		case !exists:
+			rd.Status = diffStatusCreated
+			td.Summary.Created++
+		default:
+			rd.Changes = diffAttributes(old, item, options.Replace, options.Removable)
+			switch {
+			case len(rd.Changes) == 0 && (options.AllowUpdate || options.AcceptUnchanged):
+				rd.Status = diffStatusUnchanged
+				td.Summary.Unchanged++
+			case !options.AllowUpdate:
+				rd.Status = diffStatusConflict
+				td.Summary.Conflict++
+			default:
+				rd.Status = diffStatusChanged
+				td.Summary.Changed++
+			}
+		}
+
+		td.Records = append(td.Records, rd)
+	}
+
+	return td, nil
+}
+
+// diffOutput writes the dry-run diff of an import that is compared one chunk at a time. Records are written as each
+// chunk is added. JSON output is a single tableDiff document whose summary follows the records, since it is only known
+// once they have all been written.
+type diffOutput struct {
+	out     io.Writer
+	format  string
+	summary diffSummary
+	records int
+	err     error
+}
+
+var diffMarkers = map[string]string{
+	diffStatusCreated:   "+",
+	diffStatusChanged:   "~",
+	diffStatusUnchanged: "=",
+	diffStatusConflict:  "!",
+	diffStatusDeleted:   "-",
+	diffStatusRetired:   "-",
+}
+
+// newDiffOutput creates a diffOutput for a table in the given -diff-format.
+func newDiffOutput(out io.Writer, format string, tableName string) *diffOutput {
+	do := &diffOutput{out: out, format: format}
+	if format == "json" {
+		table, _ := json.Marshal(tableName)
+		_, do.err = fmt.Fprintf(out, "{\n  \"table\": %s,\n  \"records\": [", table)
+	} else {
+		_, do.err = fmt.Fprintf(out, "Table %s:\n", tableName)
+	}
+	return do
+}
+
+// add adds the records and counts of one compared chunk.
+func (do *diffOutput) add(td tableDiff) {
+	do.summary.Created += td.Summary.Created
+	do.summary.Changed += td.Summary.Changed
+	do.summary.Unchanged += td.Summary.Unchanged
+	do.summary.Conflict += td.Summary.Conflict
+
+	for _, rd := range td.Records {
+		do.writeRecord(rd)
+	}
+}
+
+// finish adds the items that would be pruned and writes the rest of the diff.
+func (do *diffOutput) finish(pruned []string, retire bool) (diffSummary, error) {
+	for _, key := range pruned {
+		rd := recordDiff{Key: key, Status: diffStatusDeleted}
+		if retire {
+			rd.Status = diffStatusRetired
+			do.summary.Retired++
+		} else {
+			do.summary.Deleted++
+		}
+		do.writeRecord(rd)
+	}
+
+	summary := do.summary
+	if do.err != nil {
+		return summary, do.err
+	}
+
+	if do.format == "json" {
+		data, err := json.MarshalIndent(summary, "  ", "  ")
+		if err != nil {
+			return summary, err
+		}
+		closing := "\n  ]"
+		if do.records == 0 {
+			closing = "]"
+		}
+		_, err = fmt.Fprintf(do.out, "%s,\n  \"summary\": %s\n}\n", closing, data)
+		return summary, err
+	}
+
+	fmt.Fprintf(do.out, "%d created, %d changed, %d unchanged, %d conflicting", summary.Created, summary.Changed,
+		summary.Unchanged, summary.Conflict)
+	if summary.Deleted > 0 {
+		fmt.Fprintf(do.out, ", %d deleted", summary.Deleted)
+	}
+	if summary.Retired > 0 {
+		fmt.Fprintf(do.out, ", %d retired", summary.Retired)
+	}
+	_, err := fmt.Fprintf(do.out, "\n")
+	return summary, err
+}
+
+// writeRecord writes one record of the diff.
+func (do *diffOutput) writeRecord(rd recordDiff) {
+	if do.err != nil {
+		return
+	}
+
+	if do.format == "json" {
+		data, err := json.MarshalIndent(rd, "    ", "  ")
+		if err != nil {
+			do.err = err
+			return
+		}
+		separator := ","
+		if do.records == 0 {
+			separator = ""
+		}
+		do.records++
+		_, do.err = fmt.Fprintf(do.out, "%s\n    %s", separator, data)
+		return
+	}
+
+	status := rd.Status
+	if status == diffStatusConflict {
+		status = "conflict: item exists and -allow-update was not given"
+	}
+	_, do.err = fmt.Fprintf(do.out, "%s %s (%s)\n", diffMarkers[rd.Status], rd.Key, status)
+
+	for _, change := range rd.Changes {
+		if do.err == nil {
+			_, do.err = fmt.Fprintf(do.out, "    %s: %s -> %s\n", change.Field, diffValueString(change.Old), diffValueString(change.New))
+		}
+	}
+}
+
+func diffValueString(v any) string {
+	if v == nil {
+		return "(absent)"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// diffAttributes compares the attributes of an existing item with a new item, sorted by attribute name. If replace
+// is false, attributes that only exist on the old item are not reported unless they are removable.
+func diffAttributes(old map[string]ddbTypes.AttributeValue, new map[string]ddbTypes.AttributeValue, replace bool, removable []string) []fieldChange {
+	names := make([]string, 0, len(new))
+	for name := range new {
+		names = append(names, name)
+	}
+	if replace {
+		for name := range old {
+			if _, found := new[name]; !found {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for _, name := range removable {
+			_, inOld := old[name]
+			_, inNew := new[name]
+			if inOld && !inNew {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var changes []fieldChange
+	for _, name := range names {
+		oldValue := attributeValueInterface(old[name])
+		newValue := attributeValueInterface(new[name])
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, fieldChange{Field: name, Old: oldValue, New: newValue})
+		}
+	}
+
+	return changes
+}
+
+// attributeValueInterface converts an attribute value into plain Go values suitable for comparison and JSON output.
+// Numbers become json.Number and sets are sorted so that element order does not matter.
+func attributeValueInterface(av ddbTypes.AttributeValue) any {
+	switch v := av.(type) {
+	case nil:
+		return nil
+	case *ddbTypes.AttributeValueMemberS:
+		return v.Value
+	case *ddbTypes.AttributeValueMemberN:
+		return normalizeNumber(v.Value)
+	case *ddbTypes.AttributeValueMemberBOOL:
+		return v.Value
+	case *ddbTypes.AttributeValueMemberNULL:
+		return nil
+	case *ddbTypes.AttributeValueMemberB:
+		return v.Value
+	case *ddbTypes.AttributeValueMemberSS:
+		ss := append([]string(nil), v.Value...)
+		sort.Strings(ss)
+		return ss
+	case *ddbTypes.AttributeValueMemberNS:
+		ns := make([]json.Number, len(v.Value))
+		for i, n := range v.Value {
+			ns[i] = normalizeNumber(n)
+		}
+		sort.Slice(ns, func(i, j int) bool {
+			a, _ := ns[i].Float64()
+			b, _ := ns[j].Float64()
+			return a < b
+		})
+		return ns
+	case *ddbTypes.AttributeValueMemberL:
+		l := make([]any, len(v.Value))
+		for i, element := range v.Value {
+			l[i] = attributeValueInterface(element)
+		}
+		return l
+	case *ddbTypes.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for key, element := range v.Value {
+			m[key] = attributeValueInterface(element)
+		}
+		return m
+	}
+
+	return fmt.Sprintf("%v", av)
+}
+
+// normalizeNumber returns a canonical form of a DynamoDB number so that e.g. "5" and "5.0" compare equal.
+func normalizeNumber(n string) json.Number {
+	if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10))
+	}
+	if f, err := strconv.ParseFloat(n, 64); err == nil {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return json.Number(n)
+}
+
+// getItems reads the current version of the given items with BatchGetItem, keyed by keyString. Missing items are
+// not included in the result.
+func (tw *tableWriter) getItems(ctx context.Context, items []map[string]ddbTypes.AttributeValue) (map[string]map[string]ddbTypes.AttributeValue, error) {
+	result := make(map[string]map[string]ddbTypes.AttributeValue, len(items))
+	var mu sync.Mutex
+
+	batches := (len(items) + batchGetMaxKeys - 1) / batchGetMaxKeys
+	err := forEachConcurrently(ctx, tw.concurrency, batches, func(ctx context.Context, i int) error {
+		end := (i + 1) * batchGetMaxKeys
+		if end > len(items) {
+			end = len(items)
+		}
+
+		// BatchGetItem rejects duplicate keys.
+		seen := map[string]bool{}
+		keys := make([]map[string]ddbTypes.AttributeValue, 0, end-i*batchGetMaxKeys)
+		for _, item := range items[i*batchGetMaxKeys : end] {
+			key := tw.keyString(item)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, tw.key(item))
+			}
+		}
+
+		found, err := tw.batchGet(ctx, keys)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, item := range found {
+			result[tw.keyString(item)] = item
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (tw *tableWriter) batchGet(ctx context.Context, keys []map[string]ddbTypes.AttributeValue) ([]map[string]ddbTypes.AttributeValue, error) {
+	var found []map[string]ddbTypes.AttributeValue
+	requestItems := map[string]ddbTypes.KeysAndAttributes{tw.tableName: {Keys: keys, ConsistentRead: aws.Bool(true)}}
+
+	for attempt := 1; ; attempt++ {
+		output, err := tw.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+		if err != nil && !ddbutil.IsThrottlingError(err) {
+			return nil, err
+		}
+
+		if err == nil {
+			found = append(found, output.Responses[tw.tableName]...)
+			requestItems = output.UnprocessedKeys
+			if len(requestItems) == 0 {
+				return found, nil
+			}
+		}
+
+		if attempt == ddbutil.MaxRequestAttempts {
+			return nil, fmt.Errorf("%d keys still unprocessed after %d attempts", len(requestItems[tw.tableName].Keys), attempt)
+		}
+
+		err = ddbutil.SleepContext(ctx, ddbutil.RetryDelay(attempt))
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+func TestDiffOutputWritesJSONDocument(t *testing.T) {
+	chunks := []tableDiff{
+		{
+			Summary: diffSummary{Created: 1, Changed: 1},
+			Records: []recordDiff{
+				{Key: "Id=1", Status: diffStatusCreated},
+				{Key: "Id=2", Status: diffStatusChanged, Changes: []fieldChange{{Field: "Name", Old: "PEAR", New: "PEARS"}}},
+			},
+		},
+		{
+			Summary: diffSummary{Unchanged: 1},
+			Records: []recordDiff{{Key: "Id=3", Status: diffStatusUnchanged}},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		chunks      []tableDiff
+		pruned      []string
+		wantKeys    []string
+		wantSummary diffSummary
+	}{
+		{"records and pruned items", chunks, []string{"Id=4"}, []string{"Id=1", "Id=2", "Id=3", "Id=4"}, diffSummary{Created: 1, Changed: 1, Unchanged: 1, Retired: 1}},
+		{"no records", nil, nil, nil, diffSummary{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			do := newDiffOutput(&out, "json", "TCrops")
+			for _, td := range test.chunks {
+				do.add(td)
+			}
+			summary, err := do.finish(test.pruned, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got tableDiff
+			err = json.Unmarshal(out.Bytes(), &got)
+			if err != nil {
+				t.Fatalf("the diff is not valid JSON: %s\n%s", err, out.String())
+			}
+
+			if got.Table != "TCrops" {
+				t.Errorf("got table %q, want TCrops", got.Table)
+			}
+			var keys []string
+			for _, rd := range got.Records {
+				keys = append(keys, rd.Key)
+			}
+			if !reflect.DeepEqual(keys, test.wantKeys) {
+				t.Errorf("got records %q, want %q", keys, test.wantKeys)
+			}
+			if got.Summary != test.wantSummary || summary != test.wantSummary {
+				t.Errorf("got summary %+v and %+v, want %+v", got.Summary, summary, test.wantSummary)
+			}
+		})
+	}
+}
+
+func TestDiffAttributes(t *testing.T) {
+	old := map[string]ddbTypes.AttributeValue{
+		"Id":          ddbutil.N(5),
+		"Name":        ddbutil.S("APPLE"),
+		"Notes":       ddbutil.S("Pome fruit"),
+		"Ingredients": &ddbTypes.AttributeValueMemberNS{Value: []string{"2", "1"}},
+	}
+
+	tests := []struct {
+		name      string
+		new       map[string]ddbTypes.AttributeValue
+		replace   bool
+		removable []string
+		want      []fieldChange
+	}{
+		{
+			"same values in another form",
+			map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(5), "Name": ddbutil.S("APPLE"), "Notes": ddbutil.S("Pome fruit"), "Ingredients": &ddbTypes.AttributeValueMemberNS{Value: []string{"1.0", "2"}}},
+			true, nil, nil,
+		},
+		{
+			"changed attribute",
+			map[string]ddbTypes.AttributeValue{"Id": &ddbTypes.AttributeValueMemberN{Value: "5.0"}, "Name": ddbutil.S("APPLES"), "Notes": ddbutil.S("Pome fruit"), "Ingredients": &ddbTypes.AttributeValueMemberNS{Value: []string{"1", "2"}}},
+			true, nil,
+			[]fieldChange{{Field: "Name", Old: "APPLE", New: "APPLES"}},
+		},
+		{
+			"replaced item without attributes",
+			map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(5), "Name": ddbutil.S("APPLE"), "Code": ddbutil.S("APPL")},
+			true, nil,
+			[]fieldChange{
+				{Field: "Code", Old: nil, New: "APPL"},
+				{Field: "Ingredients", Old: []json.Number{"1", "2"}, New: nil},
+				{Field: "Notes", Old: "Pome fruit", New: nil},
+			},
+		},
+		{
+			"updated item without a removable attribute",
+			map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(5), "Name": ddbutil.S("APPLE")},
+			false, []string{"Notes", "Code"},
+			[]fieldChange{{Field: "Notes", Old: "Pome fruit", New: nil}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffAttributes(old, test.new, test.replace, test.removable)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got changes %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompareItemsStatuses(t *testing.T) {
+	writer, _ := newTestWriter(t, storedCrop(1, "APPLE"), storedCrop(2, "PEAR"))
+	items := []map[string]ddbTypes.AttributeValue{cropItem(1, "APPLE"), cropItem(2, "PEARS"), cropItem(3, "PLUM")}
+
+	tests := []struct {
+		name    string
+		options diffOptions
+		want    []string
+		summary diffSummary
+	}{
+		{
+			"with -allow-update",
+			diffOptions{AllowUpdate: true, Replace: true},
+			[]string{diffStatusUnchanged, diffStatusChanged, diffStatusCreated},
+			diffSummary{Created: 1, Changed: 1, Unchanged: 1},
+		},
+		{
+			"without -allow-update",
+			diffOptions{Replace: true},
+			[]string{diffStatusConflict, diffStatusConflict, diffStatusCreated},
+			diffSummary{Created: 1, Conflict: 2},
+		},
+		{
+			"when resuming without -allow-update",
+			diffOptions{AcceptUnchanged: true, Replace: true},
+			[]string{diffStatusUnchanged, diffStatusConflict, diffStatusCreated},
+			diffSummary{Created: 1, Unchanged: 1, Conflict: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			td, err := writer.compareItems(context.Background(), items, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var statuses []string
+			for _, rd := range td.Records {
+				statuses = append(statuses, rd.Status)
+			}
+			if !reflect.DeepEqual(statuses, test.want) {
+				t.Errorf("got statuses %q, want %q", statuses, test.want)
+			}
+			if td.Summary != test.summary {
+				t.Errorf("got summary %+v, want %+v", td.Summary, test.summary)
+			}
+
+			// The changed crop carries its current item and its changes.
+			if rd := td.Records[1]; rd.current == nil || (rd.Status == diffStatusChanged && len(rd.Changes) != 1) {
+				t.Errorf("got current item %v and changes %v for the changed crop", rd.current, rd.Changes)
+			}
+		})
+	}
+}
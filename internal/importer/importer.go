@@ -0,0 +1,278 @@
+// Package importer implements the import-* subcommands for PICOL datasets.
+//
+// Each entity supplies a Descriptor that maps version 1 API records onto DynamoDB items. The importer handles
+// everything else: flag parsing, reading the input file, dry runs, batched and concurrent writes, reports, and
+// keeping the id sequence up to date.
+package importer
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Descriptor describes how records of a version 1 API type are imported into a DynamoDB table.
+type Descriptor[T any] struct {
+	// Subcommand is the name of the import subcommand, e.g. "import-crops".
+	Subcommand string
+
+	// Kind is the singular noun for a record, e.g. "crop".
+	Kind string
+
+	// Noun is the plural noun for records, e.g. "crops".
+	Noun string
+
+	// TableName is the DynamoDB table name without the table prefix, e.g. "Crops".
+	TableName string
+
+	// KeyAttributes lists the key attribute names of the table. Defaults to Id.
+	KeyAttributes []string
+
+	// SequenceName is the id sequence name without the table prefix, e.g. "Crops.Id". If empty, no sequence is
+	// maintained and the -id-sequence-only flag is not offered.
+	SequenceName string
+
+	// Id returns the PICOL id of a record. May be nil if SequenceName is empty and Key is set.
+	Id func(record T) int
+
+	// Key returns the key that identifies a record in messages and reports, e.g. "Source=FRAC". Defaults to the Id,
+	// e.g. "Id=305". May be nil.
+	Key func(record T) string
+
+	// Item converts a record into its DynamoDB item, including the key attributes. Optional attributes with empty
+	// values should be omitted. A nil item skips the record, which still advances the id sequence.
+	Item func(record T) (map[string]ddbTypes.AttributeValue, error)
+
+	// Optional lists the attributes that Item may omit. When an existing item is updated in place, these are removed
+	// if the new item does not have them.
+	Optional []string
+
+	// Preserve returns attributes of existing items that are maintained outside this import. If it returns any,
+	// existing items are updated in place instead of being replaced. May be nil.
+	Preserve func() []string
+
+	// Related returns additional updates to other tables for a record, e.g. adding an ingredient to its resistance.
+	// current is the record's item as it was in the table before the import, or nil if there was none. The updates are
+	// made after the record's item is written, or in one transaction with it; see RelatedAttributes. May be nil.
+	Related func(tablePrefix string, record T, current map[string]ddbTypes.AttributeValue) []*dynamodb.UpdateItemInput
+
+	// RelatedAttributes lists the attributes of current that Related reads, e.g. the resistance ids of an ingredient.
+	// A record whose item changes any of them moves between related items, so it is written in one transaction with
+	// its related updates, on condition that the attributes are still as Related saw them; if another writer changed
+	// them, the item is read again and the updates worked out anew. Other items are written in batches and their
+	// related updates, which a later run repeats if they fail, are made afterwards. If nil, every record with related
+	// updates is written in a transaction.
+	RelatedAttributes []string
+
+	// References lists attributes of other tables that refer to this table's items by id. Items that are still
+	// referenced are never pruned. May be nil.
+	References []Reference
+
+	// Validate checks a record against the documented constraints of its fields, e.g. picolApiV1.Crop.Validate.
+	// Violations are reported as warnings, or fail the record with -strict. May be nil.
+	Validate func(record T) error
+
+	// Normalize returns a record with its text normalized, e.g. picolApiV1.Crop.Normalize. It is applied with
+	// -normalize. May be nil.
+	Normalize func(record T) T
+
+	// SortName returns the sort key of a record, which is stored in the SortName attribute with -normalize. May be nil.
+	SortName func(record T) string
+
+	// Check verifies each chunk of records before it is written. A chunk that fails stops the import, leaving the
+	// chunks before it written. May be nil.
+	Check func(records []T) error
+
+	// CheckStored verifies each chunk of records against the items already in DynamoDB, e.g. those of a table the
+	// records refer to, before it is written. Unlike Check, it is skipped with -check-only. May be nil.
+	CheckStored func(ctx context.Context, config Config, records []T) error
+
+	// CheckComplete verifies what only the whole dataset shows, e.g. that no value is missing, once every chunk has
+	// been checked. It fails the import before anything is pruned. May be nil.
+	CheckComplete func() error
+
+	// Flags registers additional subcommand flags. May be nil.
+	Flags func(flags *flag.FlagSet)
+}
+
+// Config is the environment an import runs in.
+type Config struct {
+	// AWS SDK configuration used to create the DynamoDB client.
+	AWSConfig aws.Config
+
+	// Prefix prepended to every DynamoDB table and sequence name.
+	TablePrefix string
+}
+
+// options holds the parsed flags of an import subcommand.
+type options struct {
+	allowUpdate     bool
+	idSequenceOnly  bool
+	concurrency     int
+	dryRun          bool
+	diffFormat      string
+	prune           bool
+	retire          bool
+	pruneMaxPercent float64
+	checkOnly       bool
+	reportFile      string
+	continueOnError bool
+	rejectsFile     string
+	resume          bool
+	stateDir        string
+	atomic          bool
+	allowEmpty      bool
+	minPercent      float64
+	strict          bool
+	warn            bool
+	normalize       bool
+}
+
+// Run executes an import subcommand with the given arguments and returns the process exit code.
+func Run[T any](ctx context.Context, config Config, d Descriptor[T], args []string) int {
+	var opts options
+
+	flags := flag.NewFlagSet(d.Subcommand, flag.ExitOnError)
+	flags.BoolVar(&opts.allowUpdate, "allow-update", false, fmt.Sprintf("Allow updating existing %s.", d.Noun))
+	if d.SequenceName != "" {
+		flags.BoolVar(&opts.idSequenceOnly, "id-sequence-only", false, fmt.Sprintf("Only update the id sequence, do not import %s.", d.Noun))
+	}
+	flags.IntVar(&opts.concurrency, "concurrency", DefaultConcurrency, "Number of concurrent write requests.")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Show what would change without writing anything.")
+	flags.StringVar(&opts.diffFormat, "diff-format", "text", "Format of the dry-run output: text or json.")
+	flags.BoolVar(&opts.prune, "prune", false, fmt.Sprintf("Remove %s that are not in the file, keeping the table in sync with the dataset.", d.Noun))
+	flags.BoolVar(&opts.retire, "retire", false, fmt.Sprintf("With -prune, mark missing %s as %s instead of deleting them.", d.Noun, RetiredAttribute))
+	flags.Float64Var(&opts.pruneMaxPercent, "prune-max-percent", 10, "With -prune, refuse to remove more than this percentage of the table.")
+	flags.BoolVar(&opts.allowEmpty, "allow-empty", false, fmt.Sprintf("Allow importing a file that contains no %s.", d.Noun))
+	flags.Float64Var(&opts.minPercent, "min-percent", 50, fmt.Sprintf("Fail the import, before pruning anything, if the file has fewer %s than this percentage of the items that were in the table. Rejects files are not checked. 0 disables the check.", d.Noun))
+	if d.Validate != nil {
+		flags.BoolVar(&opts.strict, "strict", false, fmt.Sprintf("Treat %s that violate the documented field constraints as failures.", d.Noun))
+		flags.BoolVar(&opts.warn, "warn", false, fmt.Sprintf("Import %s that violate the documented field constraints, reporting each violation as a warning. This is the default.", d.Noun))
+	}
+	if d.Normalize != nil || d.SortName != nil {
+		flags.BoolVar(&opts.normalize, "normalize", false, fmt.Sprintf("Trim and collapse whitespace and apply Unicode NFC in %s before importing them, and store the key they sort by in %s.", d.Noun, SortNameAttribute))
+	}
+	flags.BoolVar(&opts.checkOnly, "check-only", false, fmt.Sprintf("Only check the %s in the file, do not read or write DynamoDB.", d.Noun))
+	flags.BoolVar(&opts.continueOnError, "continue-on-error", false, fmt.Sprintf("Keep going when %s fail and write them to the rejects file.", d.Noun))
+	flags.StringVar(&opts.rejectsFile, "rejects", "", "With -continue-on-error, the file failed records are written to. Defaults to the input file name with a -rejects suffix.")
+	flags.BoolVar(&opts.atomic, "atomic", false, "Write every record in its own transaction, with its related updates, instead of in batches. Transactions cost twice the write capacity of batch writes. Without -atomic, only records that move between related items, e.g. an ingredient changing resistance, are written in transactions.")
+	flags.BoolVar(&opts.resume, "resume", false, "Skip records committed by an earlier interrupted import of the same file into the same table.")
+	flags.StringVar(&opts.stateDir, "state-dir", DefaultStateDir(), "Directory for the checkpoint files used by -resume.")
+	flags.StringVar(&opts.reportFile, "report", "", "Write a JSON report of the import to this file, or to standard output if \"-\".")
+	help := flags.Bool("help", false, "Show help.")
+
+	if d.Flags != nil {
+		d.Flags(flags)
+	}
+
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Import %s data from a JSON file.\n", d.Kind)
+		fmt.Fprintf(out, "Usage: %s %s [options] <filename>\n", os.Args[0], d.Subcommand)
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if *help {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return 0
+	}
+
+	if !ValidDiffFormat(opts.diffFormat) {
+		fmt.Fprintf(os.Stderr, "Unknown diff format: %s\n", opts.diffFormat)
+		flags.Usage()
+		return 1
+	}
+
+	if opts.prune && opts.idSequenceOnly {
+		fmt.Fprintf(os.Stderr, "-prune cannot be used with -id-sequence-only.\n")
+		return 1
+	}
+
+	if opts.retire && !opts.prune {
+		fmt.Fprintf(os.Stderr, "-retire requires -prune.\n")
+		return 1
+	}
+
+	if opts.reportFile == "-" && opts.dryRun {
+		fmt.Fprintf(os.Stderr, "-report - cannot be used with -dry-run, which writes the diff to standard output.\n")
+		return 1
+	}
+
+	if opts.strict && opts.warn {
+		fmt.Fprintf(os.Stderr, "-strict cannot be used with -warn.\n")
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "No filename specified.\n")
+		flags.Usage()
+		return 1
+	}
+
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", args[1])
+		flags.Usage()
+		return 1
+	}
+
+	filename := args[0]
+
+	if opts.resume && filename == "-" {
+		fmt.Fprintf(os.Stderr, "-resume cannot be used when reading standard input, which is not checkpointed.\n")
+		return 1
+	}
+
+	if opts.rejectsFile != "" && !opts.continueOnError {
+		fmt.Fprintf(os.Stderr, "-rejects requires -continue-on-error.\n")
+		return 1
+	}
+
+	if opts.continueOnError && opts.rejectsFile == "" {
+		if filename == "-" {
+			fmt.Fprintf(os.Stderr, "-continue-on-error requires -rejects when reading standard input.\n")
+			return 1
+		}
+		opts.rejectsFile = defaultRejectsFile(filename)
+	}
+
+	report := newReport(d.Subcommand, config.TablePrefix+d.TableName, filename)
+	report.DryRun = opts.dryRun
+
+	err := execute(ctx, config, d, opts, filename, report)
+	report.finish(err)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %s\n", err)
+	} else if report.RejectsFile != "" {
+		fmt.Fprintf(os.Stderr, "Error importing %d %s; wrote them to %s\n", report.Failed, d.Noun, report.RejectsFile)
+	}
+
+	if !opts.dryRun && !opts.checkOnly {
+		fmt.Fprintf(os.Stderr, "%s\n", report.Summary())
+	}
+
+	if opts.reportFile != "" {
+		reportErr := writeReport(opts.reportFile, report)
+		if reportErr != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %s\n", reportErr)
+			return 1
+		}
+	}
+
+	if err != nil || report.Status == ReportStatusPartial {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbtest"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// newTestWriter starts a fake DynamoDB whose TCrops table holds the given items, in their JSON wire form, and returns a
+// tableWriter for that table.
+func newTestWriter(t *testing.T, items ...string) (*tableWriter, *ddbtest.Fake) {
+	fake, config := ddbtest.New(t)
+	for _, item := range items {
+		fake.SetItem("TCrops", item)
+	}
+	return newTableWriter(dynamodb.NewFromConfig(config), "TCrops", []string{"Id"}, 4), fake
+}
+
+// cropItem returns the item of a crop with the given id and name.
+func cropItem(id int, name string) map[string]ddbTypes.AttributeValue {
+	return map[string]ddbTypes.AttributeValue{"Id": ddbutil.N(int64(id)), "Name": ddbutil.S(name)}
+}
+
+// storedCrop returns the JSON wire form of the item of a crop with the given id and name.
+func storedCrop(id int, name string) string {
+	return fmt.Sprintf(`{"Id": {"N": "%d"}, "Name": {"S": %q}}`, id, name)
+}
+
+// testCrop is the record type of the test imports.
+type testCrop struct {
+	Id   int
+	Name string
+}
+
+// testDescriptor describes the import of test crops into the Crops table. A crop without a name cannot be converted,
+// and a crop named "RESERVED" is skipped.
+func testDescriptor() Descriptor[testCrop] {
+	return Descriptor[testCrop]{
+		Subcommand:   "import-test-crops",
+		Kind:         "crop",
+		Noun:         "crops",
+		TableName:    "Crops",
+		SequenceName: "Crops.Id",
+		Id:           func(crop testCrop) int { return crop.Id },
+		Item: func(crop testCrop) (map[string]ddbTypes.AttributeValue, error) {
+			switch crop.Name {
+			case "":
+				return nil, errors.New("crop has no name")
+			case "RESERVED":
+				return nil, nil
+			}
+			return cropItem(crop.Id, crop.Name), nil
+		},
+	}
+}
+
+// testImport runs an import into a fake DynamoDB.
+type testImport struct {
+	t    *testing.T
+	fake *ddbtest.Fake
+	dir  string
+
+	config Config
+}
+
+// newTestImport starts a fake DynamoDB whose TCrops table holds the given items, in their JSON wire form, with the
+// table prefix "T".
+func newTestImport(t *testing.T, items ...string) *testImport {
+	fake, awsConfig := ddbtest.New(t)
+	for _, item := range items {
+		fake.SetItem("TCrops", item)
+	}
+	return &testImport{t: t, fake: fake, dir: t.TempDir(), config: Config{AWSConfig: awsConfig, TablePrefix: "T"}}
+}
+
+// writeFile writes records as a version 1 API response to a file in the import's directory and returns its name.
+func (ti *testImport) writeFile(name string, records ...testCrop) string {
+	ti.t.Helper()
+
+	data, err := json.Marshal(picolApiV1.Response[testCrop]{Data: records})
+	if err != nil {
+		ti.t.Fatal(err)
+	}
+	filename := filepath.Join(ti.dir, name)
+	err = os.WriteFile(filename, data, 0o644)
+	if err != nil {
+		ti.t.Fatal(err)
+	}
+	return filename
+}
+
+// run imports filename with d and the given arguments, keeping its state in the import's directory, and returns the
+// exit code and the report.
+func (ti *testImport) run(d Descriptor[testCrop], args []string, filename string) (int, Report) {
+	ti.t.Helper()
+
+	reportFile := filepath.Join(ti.dir, "report.json")
+	args = append([]string{"-state-dir", ti.dir, "-report", reportFile}, args...)
+	rc := Run(context.Background(), ti.config, d, append(args, filename))
+
+	var report Report
+	data, err := os.ReadFile(reportFile)
+	if err == nil {
+		err = json.Unmarshal(data, &report)
+	}
+	if err != nil {
+		ti.t.Fatal(err)
+	}
+	return rc, report
+}
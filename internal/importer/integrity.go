@@ -0,0 +1,208 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// ReverseSet describes a number set attribute that mirrors the references of another table, e.g.
+// Resistances.Ingredients, which lists the ingredients that refer to the resistance.
+type ReverseSet struct {
+	// TableName is the table holding the set, without the table prefix, e.g. "Resistances".
+	TableName string
+
+	// Attribute is the set attribute, e.g. "Ingredients".
+	Attribute string
+
+	// ReferringTableName is the table whose references the set mirrors, without the table prefix, e.g.
+	// "Ingredients".
+	ReferringTableName string
+
+	// ReferringAttributes are the attributes of the referring table that the set mirrors, e.g. "ResistanceId" and
+	// "ResistanceIds". Each may be a number or a number set.
+	ReferringAttributes []string
+
+	// IgnoredIds are ids that are neither checked as set owners nor as references, e.g. the null resistance, which
+	// tables imported before it stopped being stored still hold and refer to.
+	IgnoredIds []string
+}
+
+// ReverseSetResult is the outcome of checking a reverse set.
+type ReverseSetResult struct {
+	// Mismatches describes each inconsistency, in both directions.
+	Mismatches []string
+
+	// Updated is the number of sets that were rebuilt.
+	Updated int
+
+	// Dangling is the number of references to items that do not exist. Rebuilding the sets cannot fix these.
+	Dangling int
+}
+
+// CheckReverseSet compares a reverse set with the references it mirrors. If fix is true, every set that differs is
+// rebuilt from the references.
+func CheckReverseSet(ctx context.Context, config Config, set ReverseSet, fix bool, concurrency int) (ReverseSetResult, error) {
+	var result ReverseSetResult
+
+	client := dynamodb.NewFromConfig(config.AWSConfig)
+	tableName := config.TablePrefix + set.TableName
+	referringTable := config.TablePrefix + set.ReferringTableName
+
+	referrers, err := ddbutil.ScanAttributes(ctx, client, referringTable, append([]string{"Id"}, set.ReferringAttributes...))
+	if err != nil {
+		return result, fmt.Errorf("reading %s: %w", referringTable, err)
+	}
+
+	items, err := ddbutil.ScanAttributes(ctx, client, tableName, []string{"Id", set.Attribute})
+	if err != nil {
+		return result, fmt.Errorf("reading %s: %w", tableName, err)
+	}
+
+	ignored := map[string]bool{}
+	for _, id := range set.IgnoredIds {
+		ignored[id] = true
+	}
+
+	// The ids of the referrers of each item, according to the references and according to the sets.
+	expected := map[string]map[string]bool{}
+	for _, referrer := range referrers {
+		referrerId := itemId(referrer)
+		for _, attribute := range set.ReferringAttributes {
+			for _, id := range numbers(referrer[attribute]) {
+				if ignored[id] {
+					continue
+				}
+				if expected[id] == nil {
+					expected[id] = map[string]bool{}
+				}
+				expected[id][referrerId] = true
+			}
+		}
+	}
+
+	actual := map[string]map[string]bool{}
+	for _, item := range items {
+		if id := itemId(item); !ignored[id] {
+			actual[id] = keySet(numbers(item[set.Attribute]))
+		}
+	}
+
+	referrerExists := map[string]bool{}
+	for _, referrer := range referrers {
+		referrerExists[itemId(referrer)] = true
+	}
+
+	var outdated []string
+	for _, id := range sortedIds(actual) {
+		changed := false
+
+		for _, referrerId := range sortedIds(expected[id]) {
+			if !actual[id][referrerId] {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s %s is missing from %s.%s of Id %s",
+					set.ReferringTableName, referrerId, set.TableName, set.Attribute, id))
+				changed = true
+			}
+		}
+
+		for _, referrerId := range sortedIds(actual[id]) {
+			if expected[id][referrerId] {
+				continue
+			}
+			if referrerExists[referrerId] {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s.%s of Id %s lists %s %s, which does not refer to it",
+					set.TableName, set.Attribute, id, set.ReferringTableName, referrerId))
+			} else {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s.%s of Id %s lists %s %s, which does not exist",
+					set.TableName, set.Attribute, id, set.ReferringTableName, referrerId))
+			}
+			changed = true
+		}
+
+		if changed {
+			outdated = append(outdated, id)
+		}
+	}
+
+	for _, id := range sortedIds(expected) {
+		if _, found := actual[id]; found {
+			continue
+		}
+		for _, referrerId := range sortedIds(expected[id]) {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s %s refers to %s %s, which does not exist",
+				set.ReferringTableName, referrerId, set.TableName, id))
+			result.Dangling++
+		}
+	}
+
+	if !fix || len(outdated) == 0 {
+		return result, nil
+	}
+
+	inputs := make([]*dynamodb.UpdateItemInput, len(outdated))
+	for i, id := range outdated {
+		inputs[i] = rebuildSetInput(tableName, set.Attribute, id, sortedIds(expected[id]))
+	}
+
+	err = runUpdates(ctx, client, concurrency, inputs, nil)
+	if err != nil {
+		return result, fmt.Errorf("updating %s: %w", tableName, err)
+	}
+	result.Updated = len(outdated)
+
+	return result, nil
+}
+
+// rebuildSetInput returns the update that replaces a set attribute of the item with the given id. DynamoDB has no
+// empty sets, so an empty set removes the attribute.
+func rebuildSetInput(tableName string, attribute string, id string, members []string) *dynamodb.UpdateItemInput {
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(tableName),
+		Key:                      map[string]ddbTypes.AttributeValue{"Id": &ddbTypes.AttributeValueMemberN{Value: id}},
+		ExpressionAttributeNames: map[string]string{"#Id": "Id", "#Attribute": attribute},
+		ConditionExpression:      aws.String("attribute_exists(#Id)"),
+	}
+
+	if len(members) == 0 {
+		input.UpdateExpression = aws.String("REMOVE #Attribute")
+	} else {
+		input.UpdateExpression = aws.String("SET #Attribute = :Members")
+		input.ExpressionAttributeValues = map[string]ddbTypes.AttributeValue{":Members": &ddbTypes.AttributeValueMemberNS{Value: members}}
+	}
+
+	return input
+}
+
+// itemId returns the Id of an item, normalized for comparison.
+func itemId(item map[string]ddbTypes.AttributeValue) string {
+	ids := numbers(item["Id"])
+	if len(ids) == 0 {
+		return attributeValueString(item["Id"])
+	}
+	return ids[0]
+}
+
+// sortedIds returns the keys of a map of ids in numeric order.
+func sortedIds[V any](m map[string]V) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, aErr := strconv.ParseFloat(ids[i], 64)
+		b, bErr := strconv.ParseFloat(ids[j], 64)
+		if aErr != nil || bErr != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+
+	return ids
+}
@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// SortNameAttribute holds the key that an item sorts by, e.g. its case-folded name without punctuation. It is only
+// written with -normalize.
+const SortNameAttribute = "SortName"
+
+// normalized returns a copy of d that normalizes each record before validating, checking or converting it, and that
+// adds the sort key of each record to its item. Failed records are still written to the rejects file as they were read, so
+// that the rejects file can be replayed with the same flags.
+func (d Descriptor[T]) normalized() Descriptor[T] {
+	normalize := d.Normalize
+	if normalize == nil {
+		normalize = func(record T) T { return record }
+	}
+
+	item, validate, related, check, checkStored := d.Item, d.Validate, d.Related, d.Check, d.CheckStored
+
+	d.Item = func(record T) (map[string]ddbTypes.AttributeValue, error) {
+		record = normalize(record)
+		result, err := item(record)
+		if err != nil || result == nil || d.SortName == nil {
+			return result, err
+		}
+
+		if sortName := d.SortName(record); sortName != "" {
+			result[SortNameAttribute] = ddbutil.S(sortName)
+		}
+		return result, nil
+	}
+
+	if validate != nil {
+		d.Validate = func(record T) error {
+			return validate(normalize(record))
+		}
+	}
+
+	if related != nil {
+		d.Related = func(tablePrefix string, record T, current map[string]ddbTypes.AttributeValue) []*dynamodb.UpdateItemInput {
+			return related(tablePrefix, normalize(record), current)
+		}
+	}
+
+	normalizeAll := func(records []T) []T {
+		normalizedRecords := make([]T, len(records))
+		for i, record := range records {
+			normalizedRecords[i] = normalize(record)
+		}
+		return normalizedRecords
+	}
+
+	if check != nil {
+		d.Check = func(records []T) error {
+			return check(normalizeAll(records))
+		}
+	}
+
+	if checkStored != nil {
+		d.CheckStored = func(ctx context.Context, config Config, records []T) error {
+			return checkStored(ctx, config, normalizeAll(records))
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunNormalizes(t *testing.T) {
+	d := testDescriptor()
+	d.Normalize = func(crop testCrop) testCrop {
+		crop.Name = strings.TrimSpace(crop.Name)
+		return crop
+	}
+	d.SortName = func(crop testCrop) string {
+		return strings.ToLower(crop.Name)
+	}
+
+	ti := newTestImport(t)
+	ti.fake.FailWrites["TCrops/2"] = true
+	filename := ti.writeFile("crops.json", testCrop{Id: 1, Name: " APPLE "}, testCrop{Id: 2, Name: " PEAR"})
+
+	rc, _ := ti.run(d, []string{"-normalize", "-continue-on-error"}, filename)
+	if rc != 1 {
+		t.Fatalf("got exit code %d, want 1 for the failed crop", rc)
+	}
+	want := map[string]any{"Id": map[string]any{"N": "1"}, "Name": map[string]any{"S": "APPLE"}, SortNameAttribute: map[string]any{"S": "apple"}}
+	if got := ti.fake.Item("TCrops", "1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got crop %v, want %v", got, want)
+	}
+
+	// The failed crop is written to the rejects file as it was read.
+	data, err := os.ReadFile(defaultRejectsFile(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rejects rejectsResponse[testCrop]
+	err = json.Unmarshal(data, &rejects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []testCrop{{Id: 2, Name: " PEAR"}}; !reflect.DeepEqual(rejects.Data, want) {
+		t.Errorf("got rejected crops %v, want %v", rejects.Data, want)
+	}
+
+	// Without -normalize, crops are imported as they are.
+	filename = ti.writeFile("crops-3.json", testCrop{Id: 3, Name: " PLUM "})
+	rc, _ = ti.run(d, []string{"-min-percent", "0"}, filename)
+	if rc != 0 {
+		t.Fatalf("got exit code %d, want 0", rc)
+	}
+	want = map[string]any{"Id": map[string]any{"N": "3"}, "Name": map[string]any{"S": " PLUM "}}
+	if got := ti.fake.Item("TCrops", "3"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got crop %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// RetiredAttribute is set to true on items that were removed upstream when pruning in retire mode. Importing the
+// record again clears it.
+const RetiredAttribute = "Retired"
+
+// maxReportedReferences limits how many referenced items are listed when a prune is refused.
+const maxReportedReferences = 10
+
+// Reference describes an attribute in another table that refers to items of the imported table by key. The attribute
+// may be a number, a number set or, for tables keyed by a string, a string.
+type Reference struct {
+	// TableName is the referring table without the table prefix, e.g. "Ingredients".
+	TableName string
+
+	// Attribute is the referring attribute, e.g. "ResistanceId".
+	Attribute string
+}
+
+// pruneOptions controls which items are removed by a prune.
+type pruneOptions struct {
+	// Whether to mark items as retired instead of deleting them.
+	Retire bool
+
+	// The largest percentage of the table that may be pruned.
+	MaxPercent float64
+
+	// References to the imported table that must not be left dangling.
+	References []Reference
+
+	// Prefix of the referring table names.
+	TablePrefix string
+}
+
+// pruneCandidates scans the table and returns the keys of items that are not among the imported keys, given as
+// keyString values. In retire mode, items that are already retired are not returned.
+func (tw *tableWriter) pruneCandidates(ctx context.Context, imported map[string]bool, options pruneOptions) ([]map[string]ddbTypes.AttributeValue, error) {
+	projection := append(append([]string(nil), tw.keyNames...), RetiredAttribute)
+	existing, err := ddbutil.ScanAttributes(ctx, tw.client, tw.tableName, projection)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []map[string]ddbTypes.AttributeValue
+	for _, item := range existing {
+		if imported[tw.keyString(item)] {
+			continue
+		}
+
+		if retired, ok := item[RetiredAttribute].(*ddbTypes.AttributeValueMemberBOOL); ok && retired.Value && options.Retire {
+			continue
+		}
+
+		candidates = append(candidates, tw.key(item))
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return lessAttributeValue(candidates[i][tw.keyNames[0]], candidates[j][tw.keyNames[0]])
+	})
+
+	if len(candidates) > 0 && float64(len(candidates))*100 > options.MaxPercent*float64(len(existing)) {
+		return nil, fmt.Errorf("refusing to prune %d of %d items from %s, which exceeds the limit of %g%%", len(candidates), len(existing), tw.tableName, options.MaxPercent)
+	}
+
+	err = tw.checkReferences(ctx, candidates, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// checkReferences returns an error if any of the candidates is still referred to by another table.
+func (tw *tableWriter) checkReferences(ctx context.Context, candidates []map[string]ddbTypes.AttributeValue, options pruneOptions) error {
+	if len(candidates) == 0 || len(options.References) == 0 {
+		return nil
+	}
+
+	keyName := tw.keyNames[0]
+	var problems []string
+
+	for _, reference := range options.References {
+		referringTable := options.TablePrefix + reference.TableName
+		referrers, err := ddbutil.ScanAttributes(ctx, tw.client, referringTable, []string{"Id", reference.Attribute})
+		var rnfe *ddbTypes.ResourceNotFoundException
+		if errors.As(err, &rnfe) {
+			// Nothing can refer to the items from a table that does not exist yet.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error checking references from %s.%s: %w", reference.TableName, reference.Attribute, err)
+		}
+
+		referencedBy := map[string][]string{}
+		for _, referrer := range referrers {
+			for _, id := range referenceValues(referrer[reference.Attribute]) {
+				referencedBy[id] = append(referencedBy[id], attributeValueString(referrer["Id"]))
+			}
+		}
+
+		for _, candidate := range candidates {
+			id := attributeValueString(candidate[keyName])
+			if referrerIds := referencedBy[id]; len(referrerIds) > 0 {
+				sort.Strings(referrerIds)
+				problems = append(problems, fmt.Sprintf("%s=%s is referenced by %s.%s of Id %s", keyName, id, reference.TableName, reference.Attribute, strings.Join(referrerIds, ", ")))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	more := ""
+	if len(problems) > maxReportedReferences {
+		more = fmt.Sprintf("\n  ... and %d more", len(problems)-maxReportedReferences)
+		problems = problems[:maxReportedReferences]
+	}
+
+	return fmt.Errorf("refusing to prune items that are still referenced:\n  %s%s", strings.Join(problems, "\n  "), more)
+}
+
+// prune deletes or retires the items with the given keys.
+func (tw *tableWriter) prune(ctx context.Context, keys []map[string]ddbTypes.AttributeValue, retire bool) error {
+	if !retire {
+		return tw.deleteItems(ctx, keys)
+	}
+
+	inputs := make([]*dynamodb.UpdateItemInput, len(keys))
+	for i, key := range keys {
+		inputs[i] = &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(tw.tableName),
+			Key:                       key,
+			ExpressionAttributeNames:  map[string]string{"#Key": tw.keyNames[0], "#Retired": RetiredAttribute},
+			ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{":Retired": ddbutil.BOOL(true)},
+			UpdateExpression:          aws.String("SET #Retired = :Retired"),
+			ConditionExpression:       aws.String("attribute_exists(#Key)"),
+		}
+	}
+
+	return runUpdates(ctx, tw.client, tw.concurrency, inputs, nil)
+}
+
+// numbers returns the values of a number or number set attribute, normalized for comparison.
+func numbers(av ddbTypes.AttributeValue) []string {
+	switch v := av.(type) {
+	case *ddbTypes.AttributeValueMemberN:
+		return []string{string(normalizeNumber(v.Value))}
+	case *ddbTypes.AttributeValueMemberNS:
+		ns := make([]string, len(v.Value))
+		for i, n := range v.Value {
+			ns[i] = string(normalizeNumber(n))
+		}
+		return ns
+	}
+	return nil
+}
+
+// referenceValues returns the keys that a referring attribute refers to: the values of a number or number set,
+// normalized for comparison, or a string.
+func referenceValues(av ddbTypes.AttributeValue) []string {
+	if s, ok := av.(*ddbTypes.AttributeValueMemberS); ok {
+		return []string{s.Value}
+	}
+	return numbers(av)
+}
+
+// lessAttributeValue orders number attribute values numerically and anything else by its string form.
+func lessAttributeValue(a ddbTypes.AttributeValue, b ddbTypes.AttributeValue) bool {
+	an, aIsNumber := a.(*ddbTypes.AttributeValueMemberN)
+	bn, bIsNumber := b.(*ddbTypes.AttributeValueMemberN)
+	if aIsNumber && bIsNumber {
+		af, _ := normalizeNumber(an.Value).Float64()
+		bf, _ := normalizeNumber(bn.Value).Float64()
+		return af < bf
+	}
+	return attributeValueString(a) < attributeValueString(b)
+}
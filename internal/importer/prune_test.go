@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// importedCrops returns the keys of crops 1 to n.
+func importedCrops(n int) map[string]bool {
+	imported := map[string]bool{}
+	for id := 1; id <= n; id++ {
+		imported[fmt.Sprintf("Id=%d", id)] = true
+	}
+	return imported
+}
+
+func candidateKeys(writer *tableWriter, candidates []map[string]ddbTypes.AttributeValue) []string {
+	var keys []string
+	for _, candidate := range candidates {
+		keys = append(keys, writer.keyString(candidate))
+	}
+	return keys
+}
+
+func TestPruneCandidatesLimit(t *testing.T) {
+	var items []string
+	for id := 1; id <= 10; id++ {
+		items = append(items, storedCrop(id, fmt.Sprintf("CROP %d", id)))
+	}
+	writer, _ := newTestWriter(t, items...)
+
+	tests := []struct {
+		name       string
+		imported   int
+		maxPercent float64
+		want       []string
+		wantErr    string
+	}{
+		{"nothing missing", 10, 0, nil, ""},
+		{"at the limit", 9, 10, []string{"Id=10"}, ""},
+		{"over the limit", 8, 10, nil, "refusing to prune 2 of 10 items from TCrops, which exceeds the limit of 10%"},
+		// Candidates are sorted by number, not by their string form.
+		{"under a higher limit", 8, 20, []string{"Id=9", "Id=10"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates, err := writer.pruneCandidates(context.Background(), importedCrops(test.imported), pruneOptions{MaxPercent: test.maxPercent})
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := candidateKeys(writer, candidates); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got candidates %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPruneCandidatesSkipsRetiredItemsWhenRetiring(t *testing.T) {
+	writer, _ := newTestWriter(t, storedCrop(1, "APPLE"), `{"Id": {"N": "2"}, "Name": {"S": "PEAR"}, "Retired": {"BOOL": true}}`)
+
+	for _, retire := range []bool{true, false} {
+		candidates, err := writer.pruneCandidates(context.Background(), importedCrops(1), pruneOptions{Retire: retire, MaxPercent: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A retired crop is already retired, but deleting it still removes it.
+		want := []string{"Id=2"}
+		if retire {
+			want = nil
+		}
+		if got := candidateKeys(writer, candidates); !reflect.DeepEqual(got, want) {
+			t.Errorf("retire %t: got candidates %q, want %q", retire, got, want)
+		}
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	writer, fake := newTestWriter(t)
+	fake.SetItem("TLabels", `{"Id": {"N": "7"}, "CropIds": {"NS": ["2", "4"]}}`)
+	fake.SetItem("TLabels", `{"Id": {"N": "8"}, "CropIds": {"NS": ["2"]}}`)
+	fake.SetItem("TRegistrations", `{"Id": {"N": "30"}, "CropId": {"N": "3.0"}}`)
+
+	labels := Reference{TableName: "Labels", Attribute: "CropIds"}
+	registrations := Reference{TableName: "Registrations", Attribute: "CropId"}
+
+	tests := []struct {
+		name       string
+		candidates []map[string]ddbTypes.AttributeValue
+		references []Reference
+		want       []string
+	}{
+		{"unreferenced", []map[string]ddbTypes.AttributeValue{cropItem(1, ""), cropItem(5, "")}, []Reference{labels, registrations}, nil},
+		{
+			"referenced by sets and numbers",
+			[]map[string]ddbTypes.AttributeValue{cropItem(1, ""), cropItem(2, ""), cropItem(3, "")},
+			[]Reference{labels, registrations},
+			[]string{"Id=2 is referenced by Labels.CropIds of Id 7, 8", "Id=3 is referenced by Registrations.CropId of Id 30"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := writer.checkReferences(context.Background(), test.candidates, pruneOptions{References: test.references, TablePrefix: "T"})
+			if test.want == nil {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+				return
+			}
+
+			want := "refusing to prune items that are still referenced:\n  " + strings.Join(test.want, "\n  ")
+			if err == nil || err.Error() != want {
+				t.Errorf("got error %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestPruneCandidatesChecksReferences(t *testing.T) {
+	writer, fake := newTestWriter(t, storedCrop(1, "APPLE"), storedCrop(2, "PEAR"))
+	fake.SetItem("TLabels", `{"Id": {"N": "7"}, "CropIds": {"NS": ["2"]}}`)
+
+	_, err := writer.pruneCandidates(context.Background(), importedCrops(1), pruneOptions{
+		MaxPercent:  100,
+		References:  []Reference{{TableName: "Labels", Attribute: "CropIds"}},
+		TablePrefix: "T",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Id=2 is referenced by Labels.CropIds of Id 7") {
+		t.Errorf("got error %v, want crop 2 refused as referenced", err)
+	}
+}
@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// rejectsResponse is the content of a rejects file: a version 1 Response holding the records that failed, so that the
+// file can be imported again with the same subcommand once the problem is fixed. The import ignores Errors.
+type rejectsResponse[T any] struct {
+	Error   bool
+	Message string
+	Data    []T
+	Errors  []RecordError
+}
+
+// failureTracker records which input records failed during an import, keeping the failed records for the rejects
+// file. It is safe for concurrent use.
+type failureTracker[T any] struct {
+	mu              sync.Mutex
+	report          *Report
+	continueOnError bool
+
+	// The failed records and their errors, by index in the input.
+	records map[int]T
+	errors  map[int][]RecordError
+}
+
+func newFailureTracker[T any](report *Report, continueOnError bool) *failureTracker[T] {
+	return &failureTracker[T]{
+		report:          report,
+		continueOnError: continueOnError,
+		records:         map[int]T{},
+		errors:          map[int][]RecordError{},
+	}
+}
+
+// fail records an error for record, which is at index i of the input. It returns nil if the import should carry on,
+// or err if it should stop.
+func (ft *failureTracker[T]) fail(i int, record T, key string, err error) error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if _, found := ft.errors[i]; !found {
+		ft.report.Failed++
+		ft.records[i] = record
+	}
+
+	recordError := RecordError{Key: key, Error: err.Error()}
+	ft.errors[i] = append(ft.errors[i], recordError)
+	ft.report.Errors = append(ft.report.Errors, recordError)
+
+	if ft.continueOnError {
+		return nil
+	}
+	return err
+}
+
+// succeed counts an item that was written, given its diff status.
+func (ft *failureTracker[T]) succeed(status string) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if status == diffStatusCreated {
+		ft.report.Created++
+	} else {
+		ft.report.Updated++
+	}
+}
+
+// failed reports whether the record at index i of the input failed.
+func (ft *failureTracker[T]) failed(i int) bool {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	_, found := ft.errors[i]
+	return found
+}
+
+// writeRejects writes the failed records to the rejects file. Nothing is written if no record failed, or if the
+// import did not write to DynamoDB.
+func (ft *failureTracker[T]) writeRejects(opts options) error {
+	if len(ft.errors) == 0 || opts.dryRun || opts.checkOnly {
+		return nil
+	}
+
+	indexes := make([]int, 0, len(ft.errors))
+	for i := range ft.errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	rejects := rejectsResponse[T]{
+		Message: fmt.Sprintf("%d records failed to import from %s", len(indexes), ft.report.Input),
+		Data:    make([]T, 0, len(indexes)),
+	}
+	for _, i := range indexes {
+		rejects.Data = append(rejects.Data, ft.records[i])
+		rejects.Errors = append(rejects.Errors, ft.errors[i]...)
+	}
+
+	fd, err := os.Create(opts.rejectsFile)
+	if err != nil {
+		return fmt.Errorf("writing rejects: %w", err)
+	}
+	defer fd.Close()
+
+	encoder := json.NewEncoder(fd)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(rejects)
+	if err == nil {
+		err = fd.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("writing rejects: %w", err)
+	}
+
+	ft.report.RejectsFile = opts.rejectsFile
+	return nil
+}
+
+// defaultRejectsFile returns the rejects file name for an input file, e.g. crops-2023-10-17-rejects.json for
+// crops-2023-10-17.json. The name deliberately does not look like a dataset file to import-all.
+func defaultRejectsFile(filename string) string {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		ext = ".json"
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + "-rejects" + ext
+}
@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRejectsFileReplays(t *testing.T) {
+	ti := newTestImport(t)
+	ti.fake.FailWrites["TCrops/4"] = true
+	filename := ti.writeFile("crops.json",
+		testCrop{Id: 1, Name: "APPLE"},
+		testCrop{Id: 2, Name: "PEAR"},
+		testCrop{Id: 3, Name: "PLUM"},
+		testCrop{Id: 4, Name: "QUINCE"})
+
+	rc, report := ti.run(testDescriptor(), []string{"-continue-on-error"}, filename)
+	if rc != 1 || report.Failed != 1 || report.Created != 3 {
+		t.Fatalf("got exit code %d, %d failed and %d created, want 1, 1 and 3", rc, report.Failed, report.Created)
+	}
+	// The sequence reflects the crops that were written.
+	if report.Sequence == nil || report.Sequence.NextId != 4 {
+		t.Errorf("got sequence %+v, want next id 4", report.Sequence)
+	}
+
+	rejectsFile := defaultRejectsFile(filename)
+	if report.RejectsFile != rejectsFile {
+		t.Errorf("got rejects file %q, want %q", report.RejectsFile, rejectsFile)
+	}
+	data, err := os.ReadFile(rejectsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rejects rejectsResponse[testCrop]
+	err = json.Unmarshal(data, &rejects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []testCrop{{Id: 4, Name: "QUINCE"}}; !reflect.DeepEqual(rejects.Data, want) {
+		t.Errorf("got rejected crops %v, want %v", rejects.Data, want)
+	}
+	if len(rejects.Errors) != 1 || rejects.Errors[0].Key != "Id=4" {
+		t.Errorf("got rejected errors %v, want one for Id=4", rejects.Errors)
+	}
+
+	// Once the problem is fixed, the rejects file imports with the same flags. It holds one crop of the three in the
+	// table, which would fail -min-percent for a complete file.
+	delete(ti.fake.FailWrites, "TCrops/4")
+	rc, report = ti.run(testDescriptor(), []string{"-continue-on-error"}, rejectsFile)
+	if rc != 0 || report.Created != 1 {
+		t.Fatalf("got exit code %d and %d created replaying the rejects file, want 0 and 1: %s", rc, report.Created, report.Error)
+	}
+	if report.RejectsFile != "" {
+		t.Errorf("replaying the rejects file wrote rejects to %q", report.RejectsFile)
+	}
+	if report.Sequence == nil || report.Sequence.NextId != 5 {
+		t.Errorf("got sequence %+v after the replay, want next id 5", report.Sequence)
+	}
+	if keys := ti.fake.Keys("TCrops"); len(keys) != 4 {
+		t.Errorf("got crops %q, want 1 to 4", keys)
+	}
+
+	// The same crop, in a file without errors, is read as a complete dataset and fails the check.
+	complete := ti.writeFile("crops-4.json", testCrop{Id: 4, Name: "QUINCE"})
+	if rc, _ = ti.run(testDescriptor(), []string{"-allow-update"}, complete); rc == 0 {
+		t.Errorf("imported a file of one crop over a table of four")
+	}
+}
@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// ReportStatusOK is the status of an import that completed without errors.
+	ReportStatusOK = "ok"
+
+	// ReportStatusFailed is the status of an import that stopped because of an error.
+	ReportStatusFailed = "failed"
+
+	// ReportStatusPartial is the status of a -continue-on-error import in which some records failed.
+	ReportStatusPartial = "partial"
+)
+
+// Report is the machine-readable outcome of an import subcommand, written with -report. In a dry run the counts
+// describe what the import would have done.
+type Report struct {
+	Subcommand string `json:"subcommand"`
+	Table      string `json:"table"`
+
+	// Input is the imported file name, or "-" for standard input.
+	Input string `json:"input"`
+
+	// InputSHA256 is the hex-encoded SHA-256 checksum of the complete input.
+	InputSHA256 string `json:"inputSha256,omitempty"`
+
+	DryRun bool `json:"dryRun,omitempty"`
+
+	Status string `json:"status"`
+
+	// Error is the error that stopped the import, if any.
+	Error string `json:"error,omitempty"`
+
+	StartTime       time.Time `json:"startTime"`
+	DurationSeconds float64   `json:"durationSeconds"`
+
+	// Records is the number of records in the input.
+	Records int `json:"records"`
+
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+	Deleted   int `json:"deleted,omitempty"`
+	Retired   int `json:"retired,omitempty"`
+
+	// Errors lists the records that could not be imported.
+	Errors []RecordError `json:"errors,omitempty"`
+
+	// Warnings lists the records that violate the documented field constraints but were imported anyway.
+	Warnings []RecordError `json:"warnings,omitempty"`
+
+	// RejectsFile is the file the failed records were written to with -continue-on-error.
+	RejectsFile string `json:"rejectsFile,omitempty"`
+
+	// Sequence is the id sequence update, for tables that have one.
+	Sequence *SequenceReport `json:"sequence,omitempty"`
+}
+
+// RecordError is an error importing a single record.
+type RecordError struct {
+	// Key identifies the record, e.g. "Id=305".
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// SequenceReport describes the id sequence update made by an import.
+type SequenceReport struct {
+	Name   string `json:"name"`
+	NextId int64  `json:"nextId"`
+
+	// Whether the sequence was changed. It is not changed if it was already at or beyond NextId.
+	Updated bool `json:"updated"`
+}
+
+// newReport creates a report for an import that starts now.
+func newReport(subcommand string, table string, input string) *Report {
+	return &Report{
+		Subcommand: subcommand,
+		Table:      table,
+		Input:      input,
+		StartTime:  time.Now().UTC(),
+	}
+}
+
+// finish records the duration and final status of the import.
+func (r *Report) finish(err error) {
+	r.DurationSeconds = time.Since(r.StartTime).Seconds()
+	r.Status = ReportStatusOK
+	if err != nil {
+		r.Status = ReportStatusFailed
+		r.Error = err.Error()
+	} else if r.Failed > 0 && !r.DryRun {
+		r.Status = ReportStatusPartial
+	}
+}
+
+// Summary returns a one-line human-readable summary of the counts.
+func (r *Report) Summary() string {
+	summary := fmt.Sprintf("%s: %d records, %d created, %d updated, %d unchanged, %d skipped, %d failed", r.Table,
+		r.Records, r.Created, r.Updated, r.Unchanged, r.Skipped, r.Failed)
+	if r.Deleted > 0 {
+		summary += fmt.Sprintf(", %d deleted", r.Deleted)
+	}
+	if r.Retired > 0 {
+		summary += fmt.Sprintf(", %d retired", r.Retired)
+	}
+	if len(r.Warnings) > 0 {
+		summary += fmt.Sprintf(", %d warnings", len(r.Warnings))
+	}
+	return summary
+}
+
+// writeReport writes the report as JSON to filename, or to standard output if filename is "-".
+func writeReport(filename string, r *Report) error {
+	out := os.Stdout
+	if filename != "-" {
+		fd, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		out = fd
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(r)
+	if err != nil {
+		return err
+	}
+
+	if out != os.Stdout {
+		return out.Close()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReportFinishStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		failed int
+		err    error
+		want   string
+	}{
+		{"complete", false, 0, nil, ReportStatusOK},
+		{"failed records", false, 2, nil, ReportStatusPartial},
+		{"failed records in a dry run", true, 2, nil, ReportStatusOK},
+		{"error", false, 2, errors.New("writing crops: boom"), ReportStatusFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := newReport("import-crops", "TCrops", "crops.json")
+			report.DryRun = test.dryRun
+			report.Failed = test.failed
+			report.finish(test.err)
+
+			if report.Status != test.want {
+				t.Errorf("got status %q, want %q", report.Status, test.want)
+			}
+			if test.err != nil && report.Error != test.err.Error() {
+				t.Errorf("got error %q, want %q", report.Error, test.err)
+			}
+		})
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	report := Report{Table: "TCrops", Records: 9, Created: 1, Updated: 2, Unchanged: 3, Skipped: 1, Failed: 2}
+	want := "TCrops: 9 records, 1 created, 2 updated, 3 unchanged, 1 skipped, 2 failed"
+	if got := report.Summary(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	report.Deleted = 4
+	report.Warnings = []RecordError{{Key: "Id=1", Error: "bad code"}}
+	want += ", 4 deleted, 1 warnings"
+	if got := report.Summary(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRunReportsCounts(t *testing.T) {
+	ti := newTestImport(t, storedCrop(1, "APPLE"), storedCrop(2, "PEAR"))
+	filename := ti.writeFile("crops.json",
+		testCrop{Id: 1, Name: "APPLE"},
+		testCrop{Id: 2, Name: "PEARS"},
+		testCrop{Id: 3, Name: "PLUM"},
+		testCrop{Id: 4},
+		testCrop{Id: 5, Name: "RESERVED"})
+
+	rc, report := ti.run(testDescriptor(), []string{"-allow-update", "-continue-on-error"}, filename)
+	if rc != 1 {
+		t.Errorf("got exit code %d for an import with a failed record, want 1", rc)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+
+	want := Report{
+		Subcommand:  "import-test-crops",
+		Table:       "TCrops",
+		Input:       filename,
+		InputSHA256: hex.EncodeToString(sum[:]),
+		Status:      ReportStatusPartial,
+		Records:     5,
+		Created:     1,
+		Updated:     1,
+		Unchanged:   1,
+		Skipped:     1,
+		Failed:      1,
+		Errors:      []RecordError{{Key: "Id=4", Error: "crop has no name"}},
+		RejectsFile: defaultRejectsFile(filename),
+		// The failed crop 4 is not in the table, so only the skipped crop 5 raises the sequence.
+		Sequence: &SequenceReport{Name: "TCrops.Id", NextId: 6, Updated: true},
+	}
+
+	report.StartTime, report.DurationSeconds = want.StartTime, want.DurationSeconds
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %+v, want %+v", report, want)
+	}
+}
@@ -0,0 +1,878 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// chunkSize is the number of records that are compared and written together. The input is decoded one record at a
+// time and imported one chunk at a time, so memory use does not grow with the size of the dataset.
+const chunkSize = 1000
+
+// importRun holds the state of an import while its input is streamed through it in chunks.
+type importRun[T any] struct {
+	config Config
+	d      Descriptor[T]
+	opts   options
+	report *Report
+
+	client    *dynamodb.Client
+	writer    *tableWriter
+	preserve  []string
+	removable []string
+	failures  *failureTracker[T]
+	cp        *checkpointer
+
+	// Name of the input file, or "-" for standard input.
+	filename string
+
+	// Errors of the records of the current chunk that fail validation with -strict, by index in the input.
+	invalid map[int]error
+
+	// Number of items in the table before the import, for -min-percent, or -1 if it was not counted.
+	tableSize int
+
+	// Keys of the items of the records read so far, for -prune. Nil without -prune.
+	imported map[string]bool
+
+	// Dry-run output. Nil unless -dry-run was given.
+	diff *diffOutput
+
+	// Index in the input of the first record of the current chunk.
+	offset int
+
+	// Highest id of the records that are in the table, for the id sequence.
+	highestId int
+}
+
+// execute performs an import, recording its progress in report. Errors are prefixed with the step that failed.
+//
+// The input is read once. Each chunk of records is validated, checked and written before the next one is decoded, so
+// the import starts writing right away and its memory use does not grow with the size of the input. What needs the
+// whole input, i.e. refusing an empty or truncated file, Descriptor.CheckComplete and working out what to prune, is
+// done after the last chunk, before anything is pruned.
+func execute[T any](ctx context.Context, config Config, d Descriptor[T], opts options, filename string, report *Report) (err error) {
+	if opts.normalize {
+		d = d.normalized()
+	}
+
+	keyAttributes := d.KeyAttributes
+	if len(keyAttributes) == 0 {
+		keyAttributes = []string{"Id"}
+	}
+
+	// The checksum of a file, which keys its checkpoint, is computed alongside the import. Standard input can only be
+	// checksummed once it has been read, so it is not checkpointed.
+	var in io.Reader
+	var checksum func() (string, error)
+	if filename == "-" {
+		in, checksum = checksumReader(os.Stdin)
+	} else {
+		fd, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("opening file: %w", err)
+		}
+		defer fd.Close()
+		in, checksum = fd, fileChecksum(filename)
+	}
+
+	r := &importRun[T]{
+		config:    config,
+		d:         d,
+		opts:      opts,
+		report:    report,
+		filename:  filename,
+		failures:  newFailureTracker[T](report, opts.continueOnError),
+		tableSize: -1,
+	}
+
+	if !opts.checkOnly {
+		r.client = dynamodb.NewFromConfig(config.AWSConfig)
+		r.writer = newTableWriter(r.client, config.TablePrefix+d.TableName, keyAttributes, opts.concurrency)
+		if d.Preserve != nil {
+			r.preserve = d.Preserve()
+		}
+		r.removable = append(append([]string(nil), d.Optional...), RetiredAttribute)
+		if d.SortName != nil {
+			r.removable = append(r.removable, SortNameAttribute)
+		}
+
+		// The table is counted before anything is written, and compared with the input once it has been read.
+		if opts.minPercent > 0 && !opts.idSequenceOnly {
+			r.tableSize, err = r.writer.countItems(ctx)
+			if err != nil {
+				return fmt.Errorf("checking %s in %s: %w", d.Noun, filename, err)
+			}
+		}
+
+		if opts.prune {
+			r.imported = map[string]bool{}
+		}
+
+		err = r.startCheckpoint(filename, checksum)
+		if err != nil {
+			return err
+		}
+
+		if opts.dryRun {
+			r.diff = newDiffOutput(os.Stdout, opts.diffFormat, r.writer.tableName)
+		} else {
+			stopAutosave := r.cp.autosave(checkpointInterval)
+			defer func() {
+				stopAutosave()
+				if filename != "-" {
+					// An import that stops early may not have waited for the checksum that names its checkpoint.
+					if sum, err := checksum(); err == nil {
+						r.cp.setInput(checkpointFilename(opts.stateDir, report.Table, sum), sum)
+					}
+				}
+				r.cp.finish(err == nil && report.Failed == 0)
+			}()
+		}
+	}
+
+	// An error response is refused when its Error field is read, which comes before Data in the responses of the API.
+	decoder := picolApiV1.NewResponseDecoder[T](in)
+	records := make([]T, 0, chunkSize)
+	for {
+		record, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("decoding JSON: %w", err)
+		}
+
+		report.Records++
+		records = append(records, record)
+		if len(records) < chunkSize {
+			continue
+		}
+
+		err = r.importChunk(ctx, records)
+		if err != nil {
+			return err
+		}
+		r.offset += len(records)
+		records = records[:0]
+	}
+	if len(records) > 0 {
+		err = r.importChunk(ctx, records)
+		if err != nil {
+			return err
+		}
+	}
+
+	report.InputSHA256, err = checksum()
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	if report.Records == 0 && !opts.allowEmpty {
+		return fmt.Errorf("checking %s in %s: the file contains no %s; use -allow-empty to import it anyway", d.Noun, filename, d.Noun)
+	}
+
+	if d.CheckComplete != nil {
+		err = d.CheckComplete()
+		if err != nil {
+			return fmt.Errorf("checking %s in %s: %w", d.Noun, filename, err)
+		}
+	}
+
+	if opts.checkOnly {
+		return r.failures.writeRejects(opts)
+	}
+
+	// A rejects file holds only the records that failed, so it is expected to be much smaller than the table.
+	if r.tableSize >= 0 && !decoder.HasErrors {
+		err = checkSize(r.writer.tableName, report.Records, r.tableSize, opts.minPercent)
+		if err != nil {
+			return fmt.Errorf("checking %s in %s: %w", d.Noun, filename, err)
+		}
+	}
+
+	var pruned []map[string]ddbTypes.AttributeValue
+	if opts.prune {
+		pruned, err = r.writer.pruneCandidates(ctx, r.imported, pruneOptions{
+			Retire:      opts.retire,
+			MaxPercent:  opts.pruneMaxPercent,
+			References:  d.References,
+			TablePrefix: config.TablePrefix,
+		})
+		if err != nil {
+			return fmt.Errorf("pruning %s: %w", d.Noun, err)
+		}
+	}
+
+	if opts.dryRun {
+		prunedKeys := make([]string, len(pruned))
+		for i, key := range pruned {
+			prunedKeys[i] = r.writer.keyString(key)
+		}
+
+		summary, err := r.diff.finish(prunedKeys, opts.retire)
+		if err != nil {
+			return fmt.Errorf("writing diff: %w", err)
+		}
+		report.Deleted = summary.Deleted
+		report.Retired = summary.Retired
+		return nil
+	}
+
+	if len(pruned) > 0 {
+		err = r.writer.prune(ctx, pruned, opts.retire)
+		if err != nil {
+			return fmt.Errorf("pruning %s: %w", d.Noun, err)
+		}
+		if opts.retire {
+			report.Retired = len(pruned)
+		} else {
+			report.Deleted = len(pruned)
+		}
+	}
+
+	if d.SequenceName != "" {
+		sequence := SequenceReport{Name: config.TablePrefix + d.SequenceName, NextId: int64(r.highestId) + 1}
+		sequence.Updated, err = MaybeUpdateSequence(ctx, r.client, config.TablePrefix, sequence.Name, sequence.NextId)
+		if err != nil {
+			return fmt.Errorf("updating sequence: %w", err)
+		}
+		report.Sequence = &sequence
+	}
+
+	return r.failures.writeRejects(opts)
+}
+
+// startCheckpoint sets up the checkpointer that records the progress of the import, so that an interrupted import can
+// be resumed. A dry run only reads the checkpoint. With -resume, the checkpoint is loaded first, which means waiting
+// for the checksum of the input; otherwise the checkpoint is named, and saved, once the checksum is known.
+func (r *importRun[T]) startCheckpoint(filename string, checksum func() (string, error)) error {
+	opts, report := r.opts, r.report
+	if opts.dryRun && !opts.resume {
+		return nil
+	}
+
+	state := checkpoint{Subcommand: r.d.Subcommand, Table: report.Table}
+	if !opts.resume {
+		r.cp = newCheckpointer("", state)
+		if filename != "-" {
+			go func() {
+				if sum, err := checksum(); err == nil {
+					r.cp.setInput(checkpointFilename(opts.stateDir, report.Table, sum), sum)
+				}
+			}()
+		}
+		return nil
+	}
+
+	sum, err := checksum()
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+	state.InputSHA256 = sum
+	cpFilename := checkpointFilename(opts.stateDir, report.Table, sum)
+
+	previous, err := loadCheckpoint(cpFilename)
+	if err != nil {
+		return fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if previous != nil {
+		state = *previous
+	} else {
+		fmt.Fprintf(os.Stderr, "No checkpoint found for %s; starting from the beginning.\n", filename)
+	}
+	r.cp = newCheckpointer(cpFilename, state)
+	return nil
+}
+
+// item converts the record at index i of the input into its item. A record that is invalid or cannot be converted is
+// a failure. It returns a nil item for failed and skipped records.
+func (r *importRun[T]) item(i int, record T) (map[string]ddbTypes.AttributeValue, error) {
+	item, err := r.d.Item(record)
+	if err == nil && item != nil && r.imported != nil {
+		// A record that fails is still in the dataset, so its item is not pruned.
+		r.imported[r.writer.keyString(item)] = true
+	}
+	if invalid := r.invalid[i]; invalid != nil {
+		item, err = nil, invalid
+	}
+
+	if err != nil {
+		key := r.d.recordKey(record)
+		if r.failures.fail(i, record, key, err) != nil {
+			return nil, fmt.Errorf("converting %s %s: %w", r.d.Kind, key, err)
+		}
+		return nil, nil
+	}
+	if item == nil {
+		r.report.Skipped++
+	}
+	return item, nil
+}
+
+// chunk is a chunk of records being imported.
+type chunk[T any] struct {
+	records []T
+
+	// The items of the records that could be converted, and the index in records of each.
+	items       []map[string]ddbTypes.AttributeValue
+	itemRecords []int
+
+	// The items that were compared with the table, the index in records of each, and the index of each by key.
+	pending        []map[string]ddbTypes.AttributeValue
+	pendingRecords []int
+	pendingIndexes map[string]int
+
+	// The comparison of pending with the table.
+	td tableDiff
+
+	// The new and changed items.
+	writes []map[string]ddbTypes.AttributeValue
+}
+
+// fail records an error for the record at index i of the chunk.
+func (r *importRun[T]) fail(c *chunk[T], i int, key string, err error) error {
+	return r.failures.fail(r.offset+i, c.records[i], key, err)
+}
+
+// importChunk checks, compares and writes a chunk of records, starting at r.offset in the input. With -check-only, it
+// only checks the records and converts them into items.
+func (r *importRun[T]) importChunk(ctx context.Context, records []T) error {
+	d, opts, report, writer, failures, cp := r.d, r.opts, r.report, r.writer, r.failures, r.cp
+	c := &chunk[T]{records: records}
+
+	err := r.checkChunk(ctx, records)
+	if err != nil {
+		return err
+	}
+
+	if opts.checkOnly {
+		for i, record := range records {
+			_, err := r.item(r.offset+i, record)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, record := range records {
+		if opts.idSequenceOnly {
+			report.Skipped++
+			continue
+		}
+
+		item, err := r.item(r.offset+i, record)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			continue
+		}
+
+		c.items = append(c.items, item)
+		c.itemRecords = append(c.itemRecords, i)
+	}
+
+	// Records committed by an earlier run are not compared or written again.
+	for i, item := range c.items {
+		if opts.resume && cp.isCommitted(writer.keyString(item)) {
+			report.Skipped++
+			continue
+		}
+		c.pending = append(c.pending, item)
+		c.pendingRecords = append(c.pendingRecords, c.itemRecords[i])
+	}
+
+	// When resuming, items written by the interrupted run before its last checkpoint are identical, not conflicts.
+	c.td, err = writer.compareItems(ctx, c.pending, diffOptions{
+		AllowUpdate:     opts.allowUpdate,
+		AcceptUnchanged: opts.resume,
+		Replace:         len(r.preserve) == 0,
+		Removable:       r.removable,
+	})
+	if err != nil {
+		return fmt.Errorf("comparing %s: %w", d.Noun, err)
+	}
+
+	// Only new and changed items are written.
+	recordStatuses := make([]string, len(records))
+	c.pendingIndexes = make(map[string]int, len(c.pending))
+	for i, item := range c.pending {
+		rd := c.td.Records[i]
+		recordStatuses[c.pendingRecords[i]] = rd.Status
+		c.pendingIndexes[rd.Key] = i
+
+		switch rd.Status {
+		case diffStatusCreated, diffStatusChanged:
+			c.writes = append(c.writes, item)
+		case diffStatusConflict:
+			r.fail(c, c.pendingRecords[i], rd.Key, errors.New("item exists and -allow-update was not given"))
+		}
+	}
+
+	if opts.dryRun {
+		report.Created += c.td.Summary.Created
+		report.Updated += c.td.Summary.Changed
+		report.Unchanged += c.td.Summary.Unchanged
+		r.diff.add(c.td)
+		return nil
+	}
+
+	if c.td.Summary.Conflict > 0 && !opts.continueOnError {
+		return fmt.Errorf("writing %s: %d already exist; use -allow-update to update them", d.Noun, c.td.Summary.Conflict)
+	}
+
+	report.Unchanged += c.td.Summary.Unchanged
+
+	if opts.atomic {
+		err = r.writeAtomic(ctx, c)
+	} else {
+		err = r.write(ctx, c)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Failed records are left out of the sequence unless their item already exists.
+	if d.SequenceName != "" {
+		for i, record := range records {
+			if failures.failed(r.offset+i) && (recordStatuses[i] == "" || recordStatuses[i] == diffStatusCreated) {
+				continue
+			}
+			if id := d.Id(record); id > r.highestId {
+				r.highestId = id
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkChunk validates a chunk of records and runs the checks of the descriptor on it before any of it is written.
+// With -strict, a chunk with invalid records stops the import unless -continue-on-error is given, in which case the
+// invalid records fail.
+func (r *importRun[T]) checkChunk(ctx context.Context, records []T) error {
+	d, opts := r.d, r.opts
+
+	r.invalid = nil
+	if d.Validate != nil {
+		var violations []violation
+		invalid := map[int]error{}
+		for i, record := range records {
+			if err := d.Validate(record); err != nil {
+				v := newViolation(d.recordKey(record), err)
+				violations = append(violations, v)
+				invalid[r.offset+i] = v.Err
+			}
+		}
+
+		if !opts.strict {
+			warnViolations(d.Kind, violations, r.report)
+		} else if len(violations) > 0 && !opts.continueOnError {
+			return fmt.Errorf("validating %s in %s: %w", d.Noun, r.filename, violationsError(d.Noun, violations))
+		} else {
+			r.invalid = invalid
+		}
+	}
+
+	if d.Check != nil {
+		err := d.Check(records)
+		if err != nil {
+			return fmt.Errorf("checking %s in %s: %w", d.Noun, r.filename, err)
+		}
+	}
+
+	if d.CheckStored != nil && !opts.checkOnly {
+		err := d.CheckStored(ctx, r.config, records)
+		if err != nil {
+			return fmt.Errorf("checking %s in %s: %w", d.Noun, r.filename, err)
+		}
+	}
+
+	return nil
+}
+
+// write writes the new and changed items of a chunk in batches, followed by the related updates of their records.
+// Records whose item moves their related items, e.g. an ingredient moving from one resistance to another, are instead
+// written in one transaction with their related updates, so that a failure never leaves the item written without
+// them.
+func (r *importRun[T]) write(ctx context.Context, c *chunk[T]) error {
+	d, opts, writer, failures, cp := r.d, r.opts, r.writer, r.failures, r.cp
+
+	// Related updates are made for every record whose item is in the table, but not for records that an earlier run
+	// committed.
+	writes := c.writes
+	var related []relatedUpdates
+	var moves []int
+	if d.Related != nil {
+		writes = nil
+		for i, item := range c.items {
+			key := writer.keyString(item)
+			if failures.failed(r.offset+c.itemRecords[i]) || cp.isCommitted(key) {
+				continue
+			}
+
+			status := c.writeStatus(key)
+			current := r.current(c, key)
+			updates := d.Related(r.config.TablePrefix, c.records[c.itemRecords[i]], current)
+			switch {
+			case len(updates) == 0 && status == "":
+				cp.commit(key)
+			case len(updates) > 0 && status != "" && r.moves(item, current):
+				moves = append(moves, i)
+			default:
+				if status != "" {
+					writes = append(writes, item)
+				}
+				if len(updates) > 0 {
+					related = append(related, relatedUpdates{Key: key, Record: c.itemRecords[i], Status: status, Updates: updates})
+				}
+			}
+		}
+	} else {
+		for _, rd := range c.td.Records {
+			if rd.Status == diffStatusUnchanged {
+				cp.commit(rd.Key)
+			}
+		}
+	}
+
+	// An item with related updates is only complete once they are made too.
+	hasRelated := make(map[string]bool, len(related))
+	for _, ru := range related {
+		hasRelated[ru.Key] = true
+	}
+
+	result := func(item map[string]ddbTypes.AttributeValue, err error) error {
+		key := writer.keyString(item)
+		i := c.pendingIndexes[key]
+		if err != nil {
+			return r.fail(c, c.pendingRecords[i], key, err)
+		}
+
+		if !hasRelated[key] {
+			failures.succeed(c.td.Records[i].Status)
+			cp.commit(key)
+		}
+		return nil
+	}
+
+	// Batch writes replace whole items, so they are only used when nothing else maintains attributes on them.
+	var err error
+	if !opts.allowUpdate {
+		err = writer.createItems(ctx, writes, result)
+	} else if len(r.preserve) > 0 {
+		err = writer.updateItems(ctx, writes, r.removable, result)
+	} else {
+		err = writer.putItems(ctx, writes, result)
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", d.Noun, err)
+	}
+
+	// The related updates of a record whose item was written, or is unchanged, only add to the related items, so a
+	// later run makes them again if they fail.
+	err = forEachConcurrently(ctx, opts.concurrency, len(related), func(ctx context.Context, j int) error {
+		ru := related[j]
+		if failures.failed(r.offset + ru.Record) {
+			return nil
+		}
+
+		for _, uii := range ru.Updates {
+			err := ddbutil.WithRetry(ctx, func() error {
+				_, err := r.client.UpdateItem(ctx, uii)
+				return err
+			})
+			if err != nil {
+				err = fmt.Errorf("%s %s: %w", aws.ToString(uii.TableName), keyString(uii.Key), err)
+				return r.fail(c, ru.Record, ru.Key, err)
+			}
+		}
+
+		if ru.Status != "" {
+			failures.succeed(ru.Status)
+		}
+		cp.commit(ru.Key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("writing related items for %s: %w", d.Noun, err)
+	}
+
+	return r.transactRecords(ctx, c, moves)
+}
+
+// relatedUpdates are the related updates of a record whose item is written in a batch or is unchanged.
+type relatedUpdates struct {
+	// Key of the record's item, e.g. "Id=305".
+	Key string
+
+	// Index of the record in the chunk.
+	Record int
+
+	// Diff status of the record's item if it is written, otherwise empty.
+	Status string
+
+	Updates []*dynamodb.UpdateItemInput
+}
+
+// moves reports whether writing item over current changes any of the related attributes, so that the related updates
+// of the record move it between related items rather than only adding to them.
+func (r *importRun[T]) moves(item map[string]ddbTypes.AttributeValue, current map[string]ddbTypes.AttributeValue) bool {
+	if r.d.RelatedAttributes == nil {
+		return true
+	}
+	if current == nil {
+		return false
+	}
+
+	for _, attribute := range r.d.RelatedAttributes {
+		if !reflect.DeepEqual(attributeValueInterface(item[attribute]), attributeValueInterface(current[attribute])) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStatus returns the diff status of the item with the given key if it is new or changed, otherwise "".
+func (c *chunk[T]) writeStatus(key string) string {
+	pi, found := c.pendingIndexes[key]
+	if !found {
+		return ""
+	}
+
+	switch status := c.td.Records[pi].Status; status {
+	case diffStatusCreated, diffStatusChanged:
+		return status
+	}
+	return ""
+}
+
+// recordGroup returns the transaction for a record: the write of its item if status is not empty, otherwise a check
+// of its item, and its related updates. The write or check is conditioned on the related attributes of the item
+// still being as they are in current, which the updates were worked out from.
+func (r *importRun[T]) recordGroup(key string, record int, status string, item map[string]ddbTypes.AttributeValue, current map[string]ddbTypes.AttributeValue, updates []*dynamodb.UpdateItemInput) transactGroup {
+	group := transactGroup{Key: key, Record: record, Status: status}
+
+	if status != "" || len(updates) > 0 {
+		condition, names, values := r.writer.expectCondition(current, r.d.RelatedAttributes)
+		switch {
+		case status != "" && len(r.preserve) > 0:
+			uii := updateItemInput(r.writer.tableName, item, r.writer.keyNames, r.removable)
+			uii.ConditionExpression = aws.String(condition)
+			uii.ExpressionAttributeNames = mergeMaps(uii.ExpressionAttributeNames, names)
+			uii.ExpressionAttributeValues = mergeMaps(uii.ExpressionAttributeValues, values)
+			group.Items = append(group.Items, transactUpdate(uii))
+		case status != "":
+			group.Items = append(group.Items, ddbTypes.TransactWriteItem{Put: &ddbTypes.Put{
+				TableName:                 aws.String(r.writer.tableName),
+				Item:                      item,
+				ConditionExpression:       aws.String(condition),
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+			}})
+		default:
+			group.Items = append(group.Items, ddbTypes.TransactWriteItem{ConditionCheck: &ddbTypes.ConditionCheck{
+				TableName:                 aws.String(r.writer.tableName),
+				Key:                       r.writer.key(item),
+				ConditionExpression:       aws.String(condition),
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+			}})
+		}
+	}
+
+	for _, uii := range updates {
+		group.Items = append(group.Items, transactUpdate(uii))
+	}
+
+	group.ClientRequestToken = clientRequestToken(r.writer.tableName, key, group.Items)
+	return group
+}
+
+// transactRecords writes each record at the given index of c.items in one transaction with its related updates,
+// running the transactions concurrently.
+func (r *importRun[T]) transactRecords(ctx context.Context, c *chunk[T], indexes []int) error {
+	err := forEachConcurrently(ctx, r.opts.concurrency, len(indexes), func(ctx context.Context, j int) error {
+		i := indexes[j]
+		key := r.writer.keyString(c.items[i])
+
+		status, err := r.transactRecord(ctx, c, i)
+		if err != nil {
+			return r.fail(c, c.itemRecords[i], key, err)
+		}
+
+		if status != "" {
+			r.failures.succeed(status)
+		}
+		r.cp.commit(key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", r.d.Noun, err)
+	}
+
+	return nil
+}
+
+// transactRecord writes the record of c.items[i] in one transaction with its related updates and returns the diff
+// status of its item if it was written. If another writer changed the item's related attributes since it was read,
+// the transaction fails its condition; the item is then read again and the transaction rebuilt from it.
+func (r *importRun[T]) transactRecord(ctx context.Context, c *chunk[T], i int) (string, error) {
+	item, record := c.items[i], c.records[c.itemRecords[i]]
+	key := r.writer.keyString(item)
+	status := c.writeStatus(key)
+	current := r.current(c, key)
+
+	for attempt := 1; ; attempt++ {
+		var updates []*dynamodb.UpdateItemInput
+		if r.d.Related != nil {
+			updates = r.d.Related(r.config.TablePrefix, record, current)
+		}
+		group := r.recordGroup(key, c.itemRecords[i], status, item, current, updates)
+		if len(group.Items) == 0 {
+			return "", nil
+		}
+
+		err := r.writer.transact(ctx, group)
+		if !isConditionFailure(err, 0) || attempt == ddbutil.MaxRequestAttempts {
+			return status, err
+		}
+
+		found, err := r.writer.batchGet(ctx, []map[string]ddbTypes.AttributeValue{r.writer.key(item)})
+		if err != nil {
+			return "", fmt.Errorf("reading the item again: %w", err)
+		}
+
+		current = nil
+		status = diffStatusCreated
+		if len(found) > 0 {
+			if !r.opts.allowUpdate {
+				return "", errors.New("item exists and -allow-update was not given")
+			}
+			current = found[0]
+			status = diffStatusChanged
+		}
+	}
+}
+
+// mergeMaps returns a map with the entries of both a and b, or nil if both are empty.
+func mergeMaps[V any](a map[string]V, b map[string]V) map[string]V {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]V, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
+
+// recordKey returns the key that identifies a record in messages, e.g. "Id=305".
+func (d Descriptor[T]) recordKey(record T) string {
+	if d.Key != nil {
+		return d.Key(record)
+	}
+	return fmt.Sprintf("Id=%d", d.Id(record))
+}
+
+// current returns the item that the record with the given key had in the table before the import, or nil if it had
+// none.
+func (r *importRun[T]) current(c *chunk[T], key string) map[string]ddbTypes.AttributeValue {
+	pi, found := c.pendingIndexes[key]
+	if !found {
+		return nil
+	}
+	return c.td.Records[pi].current
+}
+
+// writeAtomic writes each record of a chunk with its related updates in one transaction.
+func (r *importRun[T]) writeAtomic(ctx context.Context, c *chunk[T]) error {
+	d, writer, failures, cp := r.d, r.writer, r.failures, r.cp
+
+	// Unchanged records without related updates are already complete.
+	if d.Related == nil {
+		for _, rd := range c.td.Records {
+			if rd.Status == diffStatusUnchanged {
+				cp.commit(rd.Key)
+			}
+		}
+	}
+
+	var indexes []int
+	for i, item := range c.items {
+		key := writer.keyString(item)
+		if failures.failed(r.offset+c.itemRecords[i]) || cp.isCommitted(key) {
+			continue
+		}
+
+		if d.Related == nil && c.writeStatus(key) == "" {
+			cp.commit(key)
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+
+	return r.transactRecords(ctx, c, indexes)
+}
+
+// checksumReader returns a reader of r that computes the checksum of everything read through it, and a function that
+// reads the rest of r and returns the hex-encoded SHA-256 checksum of all of it.
+func checksumReader(r io.Reader) (io.Reader, func() (string, error)) {
+	hash := sha256.New()
+	tee := io.TeeReader(r, hash)
+
+	return tee, func() (string, error) {
+		// The checksum covers the whole input, including anything the decoder did not need to read.
+		_, err := io.Copy(io.Discard, tee)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+}
+
+// fileChecksum starts computing the hex-encoded SHA-256 checksum of a file in the background, reading it separately
+// from the import, and returns a function that waits for the result.
+func fileChecksum(filename string) func() (string, error) {
+	var sum string
+	var err error
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		fd, openErr := os.Open(filename)
+		if openErr != nil {
+			err = openErr
+			return
+		}
+		defer fd.Close()
+
+		_, checksum := checksumReader(fd)
+		sum, err = checksum()
+	}()
+
+	return func() (string, error) {
+		<-done
+		return sum, err
+	}
+}
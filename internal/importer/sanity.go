@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// countItems returns the number of items in the table, or -1 if the table does not exist, which the import itself
+// reports.
+func (tw *tableWriter) countItems(ctx context.Context) (int, error) {
+	count, err := countItems(ctx, tw.client, tw.tableName)
+	var rnfe *ddbTypes.ResourceNotFoundException
+	if errors.As(err, &rnfe) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("counting items in %s: %w", tw.tableName, err)
+	}
+	return count, nil
+}
+
+// checkSize returns an error if records is less than minPercent of count, the number of items that were in the table
+// before the import. A dataset that is much smaller than the table it replaces is more likely to be truncated than to
+// reflect upstream deletions, so the import stops before pruning anything.
+func checkSize(tableName string, records int, count int, minPercent float64) error {
+	if float64(records)*100 < minPercent*float64(count) {
+		return fmt.Errorf("%d records is below the limit of %g%% of the %d items that were in %s, so the file may be truncated; nothing was pruned; use -min-percent to change the limit", records, minPercent, count, tableName)
+	}
+
+	return nil
+}
+
+// countItems returns the number of items in a table.
+func countItems(ctx context.Context, client *dynamodb.Client, tableName string) (int, error) {
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+		Select:    ddbTypes.SelectCount,
+	})
+
+	count := 0
+	for paginator.HasMorePages() {
+		var page *dynamodb.ScanOutput
+		err := ddbutil.WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		count += int(page.Count)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		records    int
+		count      int
+		minPercent float64
+		wantErr    bool
+	}{
+		{"at the limit", 50, 100, 50, false},
+		{"below the limit", 49, 100, 50, true},
+		{"larger than the table", 150, 100, 50, false},
+		{"empty table", 0, 0, 50, false},
+		{"check disabled", 1, 100, 0, false},
+		{"fractional limit", 99, 100, 99.5, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkSize("TCrops", test.records, test.count, test.minPercent)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error: %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunChecksSize(t *testing.T) {
+	stored := []string{storedCrop(1, "APPLE"), storedCrop(2, "PEAR"), storedCrop(3, "PLUM"), storedCrop(4, "QUINCE")}
+	prune := []string{"-allow-update", "-prune", "-prune-max-percent", "100"}
+
+	tests := []struct {
+		name   string
+		args   []string
+		crops  []testCrop
+		wantRC int
+
+		// The crops left in the table.
+		want int
+	}{
+		{"complete", prune, []testCrop{{1, "APPLE"}, {2, "PEAR"}, {3, "PLUM"}}, 0, 3},
+		{"truncated", prune, []testCrop{{1, "APPLE"}}, 1, 4},
+		{"truncated with -min-percent", append([]string{"-min-percent", "25"}, prune...), []testCrop{{1, "APPLE"}}, 0, 1},
+		{"empty", prune, nil, 1, 4},
+		{"empty with -allow-empty", append([]string{"-allow-empty", "-min-percent", "0"}, prune...), nil, 0, 0},
+		{"empty with -allow-empty and -min-percent", append([]string{"-allow-empty"}, prune...), nil, 1, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ti := newTestImport(t, stored...)
+			filename := ti.writeFile("crops.json", test.crops...)
+
+			rc, report := ti.run(testDescriptor(), test.args, filename)
+			if rc != test.wantRC {
+				t.Errorf("got exit code %d, want %d: %s", rc, test.wantRC, report.Error)
+			}
+			if keys := ti.fake.Keys("TCrops"); len(keys) != test.want {
+				t.Errorf("got crops %q, want %d", keys, test.want)
+			}
+		})
+	}
+}
+
+func TestRunRejectsErrorResponses(t *testing.T) {
+	ti := newTestImport(t, storedCrop(1, "APPLE"))
+	filename := filepath.Join(ti.dir, "crops.json")
+	err := os.WriteFile(filename, []byte(`{"Error": true, "Message": "upstream failure", "Data": []}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{nil, {"-allow-empty", "-min-percent", "0", "-prune", "-prune-max-percent", "100"}} {
+		rc, report := ti.run(testDescriptor(), args, filename)
+		if rc == 0 || report.Status != ReportStatusFailed {
+			t.Errorf("imported an error response with %q: exit code %d and status %q", args, rc, report.Status)
+		}
+		if keys := ti.fake.Keys("TCrops"); len(keys) != 1 {
+			t.Errorf("got crops %q after importing an error response with %q, want 1", keys, args)
+		}
+	}
+}
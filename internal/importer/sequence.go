@@ -1,4 +1,4 @@
-package main
+package importer
 
 import (
 	"context"
@@ -11,8 +11,9 @@ import (
 	"github.com/corbaltcode/picol/internal/ddbutil"
 )
 
-func MaybeUpdateSequence(ctx context.Context, ddbClient *dynamodb.Client, sequenceName string, nextId int64) error {
-	tablePrefix := CtxGetDynamoDBTablePrefix(ctx)
+// MaybeUpdateSequence sets the next id of a sequence in the <tablePrefix>Sequences table, unless the sequence is
+// already at or beyond nextId. It reports whether the sequence was changed.
+func MaybeUpdateSequence(ctx context.Context, ddbClient *dynamodb.Client, tablePrefix string, sequenceName string, nextId int64) (bool, error) {
 	sequenceTableName := aws.String(tablePrefix + "Sequences")
 
 	log.Printf("MaybeUpdateSequence: sequenceTableName=%s, sequenceName=%s, nextId=%d\n", *sequenceTableName, sequenceName, nextId)
@@ -33,9 +34,10 @@ func MaybeUpdateSequence(ctx context.Context, ddbClient *dynamodb.Client, sequen
 	if err != nil {
 		var ccfe *ddbTypes.ConditionalCheckFailedException
 		if errors.As(err, &ccfe) {
-			return nil
+			return false, nil
 		}
+		return false, err
 	}
 
-	return err
+	return true, nil
 }
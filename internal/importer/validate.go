@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+// violation is a record that violates the documented constraints of its fields.
+type violation struct {
+	// Key of the record, e.g. "Id=25".
+	Key string
+
+	// Err lists the violated constraints.
+	Err error
+}
+
+// newViolation returns the violation for a record whose Validate function returned err.
+func newViolation(key string, err error) violation {
+	var constraints []string
+	for _, ve := range picolApiV1.ValidationErrors(err) {
+		constraints = append(constraints, ve.Error())
+	}
+	if len(constraints) == 0 {
+		constraints = []string{err.Error()}
+	}
+
+	return violation{Key: key, Err: errors.New(strings.Join(constraints, "; "))}
+}
+
+// warnViolations prints the violations as warnings and adds them to the report.
+func warnViolations(kind string, violations []violation, report *Report) {
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "Warning: %s %s: %s\n", kind, v.Key, v.Err)
+		report.Warnings = append(report.Warnings, RecordError{Key: v.Key, Error: v.Err.Error()})
+	}
+}
+
+// violationsError returns the error that refuses a -strict import because of the violations.
+func violationsError(noun string, violations []violation) error {
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = fmt.Sprintf("%s: %s", v.Key, v.Err)
+	}
+
+	return fmt.Errorf("%d %s violate the documented field constraints:\n  %s", len(violations), noun, strings.Join(lines, "\n  "))
+}
@@ -0,0 +1,345 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+const (
+	// batchWriteMaxItems is the maximum number of items DynamoDB accepts in a single BatchWriteItem request.
+	batchWriteMaxItems = 25
+
+	// DefaultConcurrency is the default number of concurrent write requests per import.
+	DefaultConcurrency = 8
+)
+
+// tableWriter writes items to a single DynamoDB table using concurrent requests.
+type tableWriter struct {
+	client      *dynamodb.Client
+	tableName   string
+	keyNames    []string
+	concurrency int
+}
+
+// newTableWriter creates a tableWriter for the given table and key attributes. Concurrency values below 1 are
+// treated as 1.
+func newTableWriter(client *dynamodb.Client, tableName string, keyNames []string, concurrency int) *tableWriter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &tableWriter{client: client, tableName: tableName, keyNames: keyNames, concurrency: concurrency}
+}
+
+// key returns the key attributes of item.
+func (tw *tableWriter) key(item map[string]ddbTypes.AttributeValue) map[string]ddbTypes.AttributeValue {
+	key := make(map[string]ddbTypes.AttributeValue, len(tw.keyNames))
+	for _, keyName := range tw.keyNames {
+		key[keyName] = item[keyName]
+	}
+	return key
+}
+
+// keyString formats the key attributes of item for messages and lookups, e.g. "Id=305".
+func (tw *tableWriter) keyString(item map[string]ddbTypes.AttributeValue) string {
+	parts := make([]string, len(tw.keyNames))
+	for i, keyName := range tw.keyNames {
+		parts[i] = keyName + "=" + attributeValueString(item[keyName])
+	}
+	return strings.Join(parts, ",")
+}
+
+// writeResult receives the outcome of writing a single item: err is nil if the item was written. A non-nil return
+// value stops the remaining writes; returning nil for a failed item lets them continue. It may be called
+// concurrently.
+type writeResult func(item map[string]ddbTypes.AttributeValue, err error) error
+
+// putItems writes items with BatchWriteItem, replacing any existing items with the same key. Unprocessed items and
+// throttled requests are retried with exponential backoff.
+func (tw *tableWriter) putItems(ctx context.Context, items []map[string]ddbTypes.AttributeValue, result writeResult) error {
+	requests := make([]ddbTypes.WriteRequest, len(items))
+	for i, item := range items {
+		requests[i] = ddbTypes.WriteRequest{PutRequest: &ddbTypes.PutRequest{Item: item}}
+	}
+
+	return tw.batchWriteAll(ctx, requests, result)
+}
+
+// deleteItems deletes the items with the given keys using BatchWriteItem.
+func (tw *tableWriter) deleteItems(ctx context.Context, keys []map[string]ddbTypes.AttributeValue) error {
+	requests := make([]ddbTypes.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = ddbTypes.WriteRequest{DeleteRequest: &ddbTypes.DeleteRequest{Key: key}}
+	}
+
+	return tw.batchWriteAll(ctx, requests, nil)
+}
+
+// batchWriteAll splits the write requests into BatchWriteItem calls and runs them concurrently. If result is not nil,
+// it is called with the put item or delete key of every request and decides whether a failed batch stops the rest.
+func (tw *tableWriter) batchWriteAll(ctx context.Context, requests []ddbTypes.WriteRequest, result writeResult) error {
+	batches := (len(requests) + batchWriteMaxItems - 1) / batchWriteMaxItems
+
+	return forEachConcurrently(ctx, tw.concurrency, batches, func(ctx context.Context, i int) error {
+		end := (i + 1) * batchWriteMaxItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		batch := requests[i*batchWriteMaxItems : end]
+		unprocessed, err := tw.batchWrite(ctx, batch)
+
+		if result == nil {
+			return err
+		}
+
+		failed := make(map[string]bool, len(unprocessed))
+		for _, request := range unprocessed {
+			failed[tw.keyString(writeRequestItem(request))] = true
+		}
+
+		var stopErr error
+		for _, request := range batch {
+			item := writeRequestItem(request)
+			if !failed[tw.keyString(item)] {
+				result(item, nil)
+			} else if resultErr := result(item, err); resultErr != nil && stopErr == nil {
+				stopErr = resultErr
+			}
+		}
+
+		return stopErr
+	})
+}
+
+// batchWrite writes a single batch, retrying unprocessed items. On failure it returns the requests that were not
+// processed.
+func (tw *tableWriter) batchWrite(ctx context.Context, requests []ddbTypes.WriteRequest) ([]ddbTypes.WriteRequest, error) {
+	requestItems := map[string][]ddbTypes.WriteRequest{tw.tableName: requests}
+
+	for attempt := 1; ; attempt++ {
+		output, err := tw.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requestItems})
+		if err != nil && !ddbutil.IsThrottlingError(err) {
+			return requestItems[tw.tableName], err
+		}
+
+		if err == nil {
+			requestItems = output.UnprocessedItems
+			if len(requestItems) == 0 {
+				return nil, nil
+			}
+		}
+
+		if attempt == ddbutil.MaxRequestAttempts {
+			unprocessed := requestItems[tw.tableName]
+			return unprocessed, fmt.Errorf("%d items still unprocessed after %d attempts", len(unprocessed), attempt)
+		}
+
+		err = ddbutil.SleepContext(ctx, ddbutil.RetryDelay(attempt))
+		if err != nil {
+			return requestItems[tw.tableName], err
+		}
+	}
+}
+
+// writeRequestItem returns the item of a put request or the key of a delete request.
+func writeRequestItem(request ddbTypes.WriteRequest) map[string]ddbTypes.AttributeValue {
+	if request.PutRequest != nil {
+		return request.PutRequest.Item
+	}
+	return request.DeleteRequest.Key
+}
+
+// createItems writes items with concurrent conditional PutItem requests that fail if an item with the same key
+// already exists.
+func (tw *tableWriter) createItems(ctx context.Context, items []map[string]ddbTypes.AttributeValue, result writeResult) error {
+	return forEachConcurrently(ctx, tw.concurrency, len(items), func(ctx context.Context, i int) error {
+		pii := dynamodb.PutItemInput{
+			TableName:           aws.String(tw.tableName),
+			Item:                items[i],
+			ConditionExpression: aws.String(fmt.Sprintf("attribute_not_exists(%s)", tw.keyNames[0])),
+		}
+
+		err := ddbutil.WithRetry(ctx, func() error {
+			_, err := tw.client.PutItem(ctx, &pii)
+			return err
+		})
+		if result != nil {
+			err = result(items[i], err)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", tw.keyString(items[i]), err)
+		}
+
+		return nil
+	})
+}
+
+// updateItems writes items with concurrent UpdateItem requests. Attributes of an existing item that are not in the
+// new item are left alone, except for the removable attributes, which are removed when absent from the new item.
+func (tw *tableWriter) updateItems(ctx context.Context, items []map[string]ddbTypes.AttributeValue, removable []string, result writeResult) error {
+	inputs := make([]*dynamodb.UpdateItemInput, len(items))
+	for i, item := range items {
+		inputs[i] = updateItemInput(tw.tableName, item, tw.keyNames, removable)
+	}
+
+	var updateResult func(i int, err error) error
+	if result != nil {
+		updateResult = func(i int, err error) error {
+			return result(items[i], err)
+		}
+	}
+
+	return runUpdates(ctx, tw.client, tw.concurrency, inputs, updateResult)
+}
+
+// runUpdates executes the given UpdateItem requests concurrently. The requests may target different tables. If
+// result is not nil, it is called with the index and outcome of every request that was attempted and, like a
+// writeResult, decides whether a failure stops the rest.
+func runUpdates(ctx context.Context, client *dynamodb.Client, concurrency int, inputs []*dynamodb.UpdateItemInput, result func(i int, err error) error) error {
+	return forEachConcurrently(ctx, concurrency, len(inputs), func(ctx context.Context, i int) error {
+		err := ddbutil.WithRetry(ctx, func() error {
+			_, err := client.UpdateItem(ctx, inputs[i])
+			return err
+		})
+		if result != nil {
+			err = result(i, err)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", aws.ToString(inputs[i].TableName), keyString(inputs[i].Key), err)
+		}
+
+		return nil
+	})
+}
+
+// keyString formats an arbitrary key for messages, e.g. "Id=305".
+func keyString(key map[string]ddbTypes.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + attributeValueString(key[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// updateItemInput creates an UpdateItemInput that sets every non-key attribute of item and removes each removable
+// attribute that item does not have.
+func updateItemInput(tableName string, item map[string]ddbTypes.AttributeValue, keyNames []string, removable []string) *dynamodb.UpdateItemInput {
+	uii := dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       map[string]ddbTypes.AttributeValue{},
+		ExpressionAttributeNames:  map[string]string{},
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{},
+	}
+
+	for _, keyName := range keyNames {
+		uii.Key[keyName] = item[keyName]
+	}
+
+	names := make([]string, 0, len(item))
+	for name := range item {
+		if _, isKey := uii.Key[name]; !isKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var set, remove []string
+	for _, name := range names {
+		uii.ExpressionAttributeNames["#"+name] = name
+		uii.ExpressionAttributeValues[":"+name] = item[name]
+		set = append(set, fmt.Sprintf("#%s = :%s", name, name))
+	}
+
+	for _, name := range removable {
+		if _, present := item[name]; !present {
+			uii.ExpressionAttributeNames["#"+name] = name
+			remove = append(remove, "#"+name)
+		}
+	}
+
+	var updateExpression []string
+	if len(set) > 0 {
+		updateExpression = append(updateExpression, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		updateExpression = append(updateExpression, "REMOVE "+strings.Join(remove, ", "))
+	}
+	uii.UpdateExpression = aws.String(strings.Join(updateExpression, " "))
+
+	if len(uii.ExpressionAttributeValues) == 0 {
+		uii.ExpressionAttributeValues = nil
+	}
+
+	return &uii
+}
+
+// forEachConcurrently calls fn for each index in [0, n) using up to concurrency goroutines. It stops starting new
+// calls after the first error and returns that error.
+func forEachConcurrently(ctx context.Context, concurrency int, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := fn(ctx, i)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return firstErr
+}
+
+// attributeValueString formats a scalar attribute value for messages.
+func attributeValueString(av ddbTypes.AttributeValue) string {
+	switch v := av.(type) {
+	case *ddbTypes.AttributeValueMemberN:
+		return v.Value
+	case *ddbTypes.AttributeValueMemberS:
+		return v.Value
+	}
+	return fmt.Sprintf("%v", av)
+}
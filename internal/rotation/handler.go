@@ -0,0 +1,67 @@
+package rotation
+
+import (
+	"encoding/json"
+	"net/http"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+// DefaultMaxAlternatives is the number of alternative labels suggested when a request does not say.
+const DefaultMaxAlternatives = 10
+
+// Request is the body of a rotation advice request.
+type Request struct {
+	// The applications made so far in the season, in order.
+	Applications []Application
+
+	// The crop and pest of the season. Alternative labels are only suggested for both.
+	CropId int `json:",omitempty"`
+	PestId int `json:",omitempty"`
+
+	// The largest number of alternative labels to suggest. If nil, defaults to DefaultMaxAlternatives for requests
+	// with a crop and pest, and to 0 for others.
+	MaxAlternatives *int `json:",omitempty"`
+}
+
+// NewHandler returns an http.Handler that answers POSTed Requests with a version 1 API Response whose Data holds a
+// single Advice. Invalid requests get a Response with Error set.
+func NewHandler(catalog *Catalog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeResponse(w, http.StatusMethodNotAllowed, picolApiV1.Response[Advice]{Error: true, Message: "use POST"})
+			return
+		}
+
+		var request Request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, picolApiV1.Response[Advice]{Error: true, Message: "decoding request: " + err.Error()})
+			return
+		}
+
+		registration := Registration{CropId: request.CropId, PestId: request.PestId}
+
+		maxAlternatives := 0
+		if request.MaxAlternatives != nil {
+			maxAlternatives = *request.MaxAlternatives
+		} else if registration.CropId != 0 && registration.PestId != 0 {
+			maxAlternatives = DefaultMaxAlternatives
+		}
+
+		advice, err := catalog.Advise(request.Applications, registration, maxAlternatives)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, picolApiV1.Response[Advice]{Error: true, Message: err.Error()})
+			return
+		}
+
+		writeResponse(w, http.StatusOK, picolApiV1.Response[Advice]{Data: []Advice{advice}})
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, response picolApiV1.Response[Advice]) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,85 @@
+package rotation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	picolApiV1 "github.com/corbaltcode/picol/internal/api_model/v1"
+)
+
+// serve sends a request to a handler for testCatalog and returns the status and decoded response.
+func serve(t *testing.T, method string, body string) (int, picolApiV1.Response[Advice]) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	NewHandler(testCatalog()).ServeHTTP(recorder, httptest.NewRequest(method, "/v1/rotation-advice", strings.NewReader(body)))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", contentType)
+	}
+
+	var response picolApiV1.Response[Advice]
+	err := json.NewDecoder(recorder.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recorder.Code, response
+}
+
+func TestHandlerAdvises(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []int
+	}{
+		{"default number of alternatives", `{"Applications": [{"LabelId": 1}], "CropId": 10, "PestId": 20}`, []int{3, 2}},
+		{"fewer alternatives", `{"Applications": [{"LabelId": 1}], "CropId": 10, "PestId": 20, "MaxAlternatives": 1}`, []int{3}},
+		{"no crop or pest", `{"Applications": [{"IngredientIds": [101]}]}`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, response := serve(t, http.MethodPost, test.body)
+			if status != http.StatusOK || response.Error {
+				t.Fatalf("got status %d and message %q, want 200", status, response.Message)
+			}
+			if len(response.Data) != 1 {
+				t.Fatalf("got %d advice objects, want 1", len(response.Data))
+			}
+			if got := labelIds(response.Data[0].Alternatives); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got alternatives %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHandlerRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		status  int
+		message string
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed, "use POST"},
+		{"malformed JSON", http.MethodPost, `{"Applications": [`, http.StatusBadRequest, "decoding request: unexpected EOF"},
+		{"unknown label", http.MethodPost, `{"Applications": [{"LabelId": 99}]}`, http.StatusBadRequest, "application 1: label 99 does not exist"},
+		{"alternatives without a crop", http.MethodPost, `{"Applications": [{"LabelId": 1}], "PestId": 20, "MaxAlternatives": 5}`, http.StatusBadRequest, ErrNoRegistration.Error()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, response := serve(t, test.method, test.body)
+			if status != test.status {
+				t.Errorf("got status %d, want %d", status, test.status)
+			}
+			if !response.Error || response.Message != test.message {
+				t.Errorf("got Error %t and message %q, want an error with message %q", response.Error, response.Message, test.message)
+			}
+		})
+	}
+}
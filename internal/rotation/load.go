@@ -0,0 +1,55 @@
+package rotation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+	"github.com/corbaltcode/picol/internal/ddbutil"
+)
+
+// LoadCatalog reads the resistances, labels, crops and pests from DynamoDB. Only the attributes that advice needs are read.
+func LoadCatalog(ctx context.Context, client *dynamodb.Client, tablePrefix string) (*Catalog, error) {
+	var resistances []ddbmodel.Resistance
+	err := scan(ctx, client, tablePrefix+"Resistances", []string{"Id", "Source", "Code", "MethodOfAction", "Ingredients", "Retired"}, &resistances)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []ddbmodel.Label
+	err = scan(ctx, client, tablePrefix+"Labels", []string{"Id", "Name", "EpaNumber", "IngredientIds", "CropIds", "PestIds", "Retired"}, &labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var crops []ddbmodel.Crop
+	err = scan(ctx, client, tablePrefix+"Crops", []string{"Id", "Name", "Retired"}, &crops)
+	if err != nil {
+		return nil, err
+	}
+
+	var pests []ddbmodel.Pest
+	err = scan(ctx, client, tablePrefix+"Pests", []string{"Id", "Name", "Retired"}, &pests)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCatalog(resistances, labels, crops, pests), nil
+}
+
+// scan reads the given attributes of every item in a table into out, a pointer to a slice of stored items.
+func scan(ctx context.Context, client *dynamodb.Client, tableName string, attributes []string, out any) error {
+	items, err := ddbutil.ScanAttributes(ctx, client, tableName, attributes)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", tableName, err)
+	}
+
+	err = attributevalue.UnmarshalListOfMaps(items, out)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", tableName, err)
+	}
+
+	return nil
+}
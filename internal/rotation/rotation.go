@@ -0,0 +1,350 @@
+// Package rotation advises on rotating resistance groups (modes of action) over a season.
+//
+// Advice is based on the resistance groups of the ingredients applied so far, taken from Resistances.Ingredients, and
+// on the ingredients of each label. Alternative labels are those registered for the season's crop and pest, taken from
+// Labels.CropIds and Labels.PestIds.
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+)
+
+// ErrNoRegistration is returned for requests for alternatives that do not give both a crop and a pest.
+var ErrNoRegistration = errors.New("alternatives are only suggested for a crop and a pest")
+
+// Group is a resistance group, e.g. FRAC 3.
+type Group struct {
+	Id             int
+	Source         string
+	Code           string
+	MethodOfAction string
+}
+
+func (g Group) String() string {
+	if g.MethodOfAction == "" {
+		return fmt.Sprintf("%s %s", g.Source, g.Code)
+	}
+	return fmt.Sprintf("%s %s (%s)", g.Source, g.Code, g.MethodOfAction)
+}
+
+// Label is a pesticide label and the resistance groups of its ingredients.
+type Label struct {
+	Id        int
+	Name      string
+	EpaNumber string
+	Groups    []Group
+}
+
+// Registration is the crop and pest of a season. Alternative labels must be registered for both.
+type Registration struct {
+	CropId int
+	PestId int
+}
+
+// Application is one application in a season: either a label, or the ingredients applied, e.g. a tank mix.
+type Application struct {
+	LabelId       int   `json:",omitempty"`
+	IngredientIds []int `json:",omitempty"`
+}
+
+// AppliedGroups is an application and the resistance groups it used.
+type AppliedGroups struct {
+	Application
+
+	// The label, if the application was given as a label.
+	Label *Label `json:",omitempty"`
+
+	Groups []Group
+}
+
+// GroupUse is a resistance group and the number of applications in the season that used it.
+type GroupUse struct {
+	Group
+	Applications int
+}
+
+// Advice is the rotation advice for a season.
+type Advice struct {
+	// The resistance groups of each application, in order.
+	Applications []AppliedGroups
+
+	// Every resistance group used in the season, most used first.
+	Groups []GroupUse
+
+	// Consecutive applications from the same group, and applications without a known group.
+	Warnings []string
+
+	// The names of the crop and pest that alternatives are registered for, if any were requested.
+	Crop string `json:",omitempty"`
+	Pest string `json:",omitempty"`
+
+	// Labels registered for the crop and pest whose groups differ from those of the last application with a known
+	// group, preferring groups used least in the season.
+	Alternatives []Label
+}
+
+// Catalog holds the resistance groups, labels, crops and pests that advice is based on.
+type Catalog struct {
+	groups           map[int]Group
+	ingredientGroups map[int][]int
+	labels           map[int]ddbmodel.Label
+	crops            map[int]string
+	pests            map[int]string
+}
+
+// NewCatalog creates a catalog from the stored resistances, labels, crops and pests. Retired items are left out.
+func NewCatalog(resistances []ddbmodel.Resistance, labels []ddbmodel.Label, crops []ddbmodel.Crop, pests []ddbmodel.Pest) *Catalog {
+	c := &Catalog{
+		groups:           map[int]Group{},
+		ingredientGroups: map[int][]int{},
+		labels:           map[int]ddbmodel.Label{},
+		crops:            map[int]string{},
+		pests:            map[int]string{},
+	}
+
+	for _, resistance := range resistances {
+		if resistance.Retired {
+			continue
+		}
+		c.groups[resistance.Id] = Group{
+			Id:             resistance.Id,
+			Source:         resistance.Source,
+			Code:           resistance.Code,
+			MethodOfAction: resistance.MethodOfAction,
+		}
+		for _, ingredientId := range resistance.Ingredients {
+			c.ingredientGroups[ingredientId] = append(c.ingredientGroups[ingredientId], resistance.Id)
+		}
+	}
+
+	for _, groupIds := range c.ingredientGroups {
+		sort.Ints(groupIds)
+	}
+
+	for _, label := range labels {
+		if !label.Retired {
+			c.labels[label.Id] = label
+		}
+	}
+
+	for _, crop := range crops {
+		if !crop.Retired {
+			c.crops[crop.Id] = crop.Name
+		}
+	}
+
+	for _, pest := range pests {
+		if !pest.Retired {
+			c.pests[pest.Id] = pest.Name
+		}
+	}
+
+	return c
+}
+
+// Advise returns the rotation advice for the applications of a season, in the order they were made. At most
+// maxAlternatives alternative labels registered for the season's crop and pest are suggested; 0 suggests none and
+// needs no registration.
+func (c *Catalog) Advise(applications []Application, registration Registration, maxAlternatives int) (Advice, error) {
+	var advice Advice
+
+	if maxAlternatives > 0 && (registration.CropId == 0 || registration.PestId == 0) {
+		return Advice{}, ErrNoRegistration
+	}
+
+	if registration.CropId != 0 {
+		crop, found := c.crops[registration.CropId]
+		if !found {
+			return Advice{}, fmt.Errorf("crop %d does not exist", registration.CropId)
+		}
+		advice.Crop = crop
+	}
+
+	if registration.PestId != 0 {
+		pest, found := c.pests[registration.PestId]
+		if !found {
+			return Advice{}, fmt.Errorf("pest %d does not exist", registration.PestId)
+		}
+		advice.Pest = pest
+	}
+
+	uses := map[int]int{}
+	applied := map[int]bool{}
+	for i, application := range applications {
+		var ingredientIds []int
+		ag := AppliedGroups{Application: application}
+
+		switch {
+		case application.LabelId != 0 && len(application.IngredientIds) > 0:
+			return Advice{}, fmt.Errorf("application %d has both a label and ingredients", i+1)
+		case application.LabelId != 0:
+			label, found := c.labels[application.LabelId]
+			if !found {
+				return Advice{}, fmt.Errorf("application %d: label %d does not exist", i+1, application.LabelId)
+			}
+			ingredientIds = label.IngredientIds
+			applied[label.Id] = true
+			l := c.label(label)
+			ag.Label = &l
+		case len(application.IngredientIds) > 0:
+			ingredientIds = application.IngredientIds
+		default:
+			return Advice{}, fmt.Errorf("application %d has neither a label nor ingredients", i+1)
+		}
+
+		for _, groupId := range c.groupIds(ingredientIds) {
+			ag.Groups = append(ag.Groups, c.groups[groupId])
+			uses[groupId]++
+		}
+
+		if len(ag.Groups) == 0 {
+			advice.Warnings = append(advice.Warnings, fmt.Sprintf("application %d has no known resistance group", i+1))
+		}
+
+		if i > 0 {
+			for _, shared := range sharedGroups(advice.Applications[i-1].Groups, ag.Groups) {
+				advice.Warnings = append(advice.Warnings, fmt.Sprintf("applications %d and %d both use %s", i, i+1, shared))
+			}
+		}
+
+		advice.Applications = append(advice.Applications, ag)
+	}
+
+	for groupId, count := range uses {
+		advice.Groups = append(advice.Groups, GroupUse{Group: c.groups[groupId], Applications: count})
+	}
+	sort.Slice(advice.Groups, func(i, j int) bool {
+		a, b := advice.Groups[i], advice.Groups[j]
+		if a.Applications != b.Applications {
+			return a.Applications > b.Applications
+		}
+		return a.Id < b.Id
+	})
+
+	if maxAlternatives > 0 {
+		// An application without a known group says nothing about what to rotate away from.
+		var last []Group
+		for i := len(advice.Applications) - 1; i >= 0 && len(last) == 0; i-- {
+			last = advice.Applications[i].Groups
+		}
+		advice.Alternatives = c.alternatives(registration, last, uses, applied, maxAlternatives)
+	}
+
+	return advice, nil
+}
+
+// alternatives returns labels registered for the crop and pest that share no group with last, other than those
+// already applied. Labels whose groups were used fewer times in the season come first.
+func (c *Catalog) alternatives(registration Registration, last []Group, uses map[int]int, applied map[int]bool, max int) []Label {
+	excluded := map[int]bool{}
+	for _, group := range last {
+		excluded[group.Id] = true
+	}
+
+	type candidate struct {
+		label Label
+		uses  int
+	}
+
+	var candidates []candidate
+	for _, label := range c.labels {
+		if applied[label.Id] || !contains(label.CropIds, registration.CropId) || !contains(label.PestIds, registration.PestId) {
+			continue
+		}
+
+		groupIds := c.groupIds(label.IngredientIds)
+		if len(groupIds) == 0 {
+			continue
+		}
+
+		suitable := true
+		seasonUses := 0
+		for _, groupId := range groupIds {
+			if excluded[groupId] {
+				suitable = false
+				break
+			}
+			seasonUses += uses[groupId]
+		}
+
+		if suitable {
+			candidates = append(candidates, candidate{label: c.label(label), uses: seasonUses})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.uses != b.uses {
+			return a.uses < b.uses
+		}
+		if a.label.Name != b.label.Name {
+			return a.label.Name < b.label.Name
+		}
+		return a.label.Id < b.label.Id
+	})
+
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+
+	labels := make([]Label, len(candidates))
+	for i, candidate := range candidates {
+		labels[i] = candidate.label
+	}
+	return labels
+}
+
+// label returns a stored label with the resistance groups of its ingredients.
+func (c *Catalog) label(label ddbmodel.Label) Label {
+	l := Label{Id: label.Id, Name: label.Name, EpaNumber: label.EpaNumber}
+	for _, groupId := range c.groupIds(label.IngredientIds) {
+		l.Groups = append(l.Groups, c.groups[groupId])
+	}
+	return l
+}
+
+// groupIds returns the distinct resistance groups of the given ingredients, in order of id.
+func (c *Catalog) groupIds(ingredientIds []int) []int {
+	seen := map[int]bool{}
+	var groupIds []int
+	for _, ingredientId := range ingredientIds {
+		for _, groupId := range c.ingredientGroups[ingredientId] {
+			if !seen[groupId] {
+				seen[groupId] = true
+				groupIds = append(groupIds, groupId)
+			}
+		}
+	}
+	sort.Ints(groupIds)
+	return groupIds
+}
+
+// sharedGroups returns the groups that are in both a and b.
+func sharedGroups(a []Group, b []Group) []Group {
+	inA := map[int]bool{}
+	for _, group := range a {
+		inA[group.Id] = true
+	}
+
+	var shared []Group
+	for _, group := range b {
+		if inA[group.Id] {
+			shared = append(shared, group)
+		}
+	}
+	return shared
+}
+
+// contains reports whether id is one of ids.
+func contains(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
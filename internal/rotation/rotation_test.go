@@ -0,0 +1,170 @@
+package rotation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/corbaltcode/picol/internal/ddbmodel"
+)
+
+const (
+	apple = 10
+	pear  = 11
+	scab  = 20
+	mites = 21
+)
+
+var (
+	frac3  = Group{Id: 3, Source: "FRAC", Code: "3", MethodOfAction: "DMI fungicides"}
+	frac7  = Group{Id: 7, Source: "FRAC", Code: "7", MethodOfAction: "SDHI fungicides"}
+	fracM3 = Group{Id: 40, Source: "FRAC", Code: "M3", MethodOfAction: "Dithiocarbamates"}
+)
+
+// testCatalog returns a catalog with three fungicide groups, one ingredient in each, and labels registered for apple
+// scab unless their name says otherwise.
+func testCatalog() *Catalog {
+	resistances := []ddbmodel.Resistance{
+		{Id: frac3.Id, Source: frac3.Source, Code: frac3.Code, MethodOfAction: frac3.MethodOfAction, Ingredients: []int{101}},
+		{Id: frac7.Id, Source: frac7.Source, Code: frac7.Code, MethodOfAction: frac7.MethodOfAction, Ingredients: []int{102}},
+		{Id: fracM3.Id, Source: fracM3.Source, Code: fracM3.Code, MethodOfAction: fracM3.MethodOfAction, Ingredients: []int{103}},
+		{Id: 50, Source: "FRAC", Code: "50", Ingredients: []int{104}, Retired: true},
+	}
+
+	labels := []ddbmodel.Label{
+		{Id: 1, Name: "DMI", EpaNumber: "1-1", IngredientIds: []int{101}, CropIds: []int{apple}, PestIds: []int{scab}},
+		{Id: 2, Name: "SDHI", EpaNumber: "1-2", IngredientIds: []int{102}, CropIds: []int{apple, pear}, PestIds: []int{scab}},
+		{Id: 3, Name: "DITHIOCARBAMATE", EpaNumber: "1-3", IngredientIds: []int{103}, CropIds: []int{apple}, PestIds: []int{scab}},
+		{Id: 4, Name: "DMI AND SDHI", EpaNumber: "1-4", IngredientIds: []int{101, 102}, CropIds: []int{apple}, PestIds: []int{scab}},
+		{Id: 5, Name: "PEAR ONLY", EpaNumber: "1-5", IngredientIds: []int{103}, CropIds: []int{pear}, PestIds: []int{scab}},
+		{Id: 6, Name: "MITES ONLY", EpaNumber: "1-6", IngredientIds: []int{103}, CropIds: []int{apple}, PestIds: []int{mites}},
+		{Id: 7, Name: "RETIRED", EpaNumber: "1-7", IngredientIds: []int{103}, CropIds: []int{apple}, PestIds: []int{scab}, Retired: true},
+		{Id: 8, Name: "RETIRED GROUP", EpaNumber: "1-8", IngredientIds: []int{104}, CropIds: []int{apple}, PestIds: []int{scab}},
+	}
+
+	crops := []ddbmodel.Crop{{Id: apple, Name: "APPLE"}, {Id: pear, Name: "PEAR"}}
+	pests := []ddbmodel.Pest{{Id: scab, Name: "SCAB"}, {Id: mites, Name: "MITES"}}
+
+	return NewCatalog(resistances, labels, crops, pests)
+}
+
+func labelIds(labels []Label) []int {
+	var ids []int
+	for _, label := range labels {
+		ids = append(ids, label.Id)
+	}
+	return ids
+}
+
+func TestAdviseReportsGroupsAndWarnings(t *testing.T) {
+	applications := []Application{{LabelId: 1}, {IngredientIds: []int{101, 102}}, {IngredientIds: []int{999}}, {LabelId: 2}}
+
+	advice, err := testCatalog().Advise(applications, Registration{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var groups [][]Group
+	for _, ag := range advice.Applications {
+		groups = append(groups, ag.Groups)
+	}
+	wantGroups := [][]Group{{frac3}, {frac3, frac7}, nil, {frac7}}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Errorf("got application groups %v, want %v", groups, wantGroups)
+	}
+
+	if label := advice.Applications[0].Label; label == nil || label.Name != "DMI" {
+		t.Errorf("got label %v for the first application, want DMI", label)
+	}
+
+	wantUses := []GroupUse{{Group: frac3, Applications: 2}, {Group: frac7, Applications: 2}}
+	if !reflect.DeepEqual(advice.Groups, wantUses) {
+		t.Errorf("got group uses %v, want %v", advice.Groups, wantUses)
+	}
+
+	wantWarnings := []string{
+		"applications 1 and 2 both use FRAC 3 (DMI fungicides)",
+		"application 3 has no known resistance group",
+	}
+	if !reflect.DeepEqual(advice.Warnings, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", advice.Warnings, wantWarnings)
+	}
+
+	if advice.Alternatives != nil {
+		t.Errorf("got alternatives %v without asking for any", advice.Alternatives)
+	}
+}
+
+func TestAdviseSuggestsAlternativesRegisteredForCropAndPest(t *testing.T) {
+	tests := []struct {
+		name         string
+		applications []Application
+		registration Registration
+		max          int
+		want         []int
+	}{
+		// Label 4 shares FRAC 3, labels 5 and 6 are not registered for apple scab, 7 is retired and 8 has no
+		// known group.
+		{"after a DMI", []Application{{LabelId: 1}}, Registration{apple, scab}, 10, []int{3, 2}},
+		{"at most one", []Application{{LabelId: 1}}, Registration{apple, scab}, 1, []int{3}},
+		{"least used groups first", []Application{{LabelId: 3}, {IngredientIds: []int{101}}}, Registration{apple, scab}, 10, []int{2}},
+		{"from the last application with a group", []Application{{LabelId: 2}, {IngredientIds: []int{999}}}, Registration{apple, scab}, 10, []int{3, 1}},
+		{"other crop", []Application{{LabelId: 1}}, Registration{pear, scab}, 10, []int{5, 2}},
+		{"other pest", []Application{{LabelId: 1}}, Registration{apple, mites}, 10, []int{6}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			advice, err := testCatalog().Advise(test.applications, test.registration, test.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := labelIds(advice.Alternatives); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got alternatives %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAdviseNamesCropAndPest(t *testing.T) {
+	advice, err := testCatalog().Advise([]Application{{LabelId: 1}}, Registration{apple, scab}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advice.Crop != "APPLE" || advice.Pest != "SCAB" {
+		t.Errorf("got crop %q and pest %q, want APPLE and SCAB", advice.Crop, advice.Pest)
+	}
+}
+
+func TestAdviseRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		applications []Application
+		registration Registration
+		max          int
+		want         string
+	}{
+		{"label and ingredients", []Application{{LabelId: 1, IngredientIds: []int{101}}}, Registration{}, 0, "application 1 has both a label and ingredients"},
+		{"empty application", []Application{{LabelId: 1}, {}}, Registration{}, 0, "application 2 has neither a label nor ingredients"},
+		{"unknown label", []Application{{LabelId: 99}}, Registration{}, 0, "application 1: label 99 does not exist"},
+		{"retired label", []Application{{LabelId: 7}}, Registration{}, 0, "application 1: label 7 does not exist"},
+		{"alternatives without a pest", []Application{{LabelId: 1}}, Registration{CropId: apple}, 1, ErrNoRegistration.Error()},
+		{"alternatives without a crop", []Application{{LabelId: 1}}, Registration{PestId: scab}, 1, ErrNoRegistration.Error()},
+		{"unknown crop", []Application{{LabelId: 1}}, Registration{99, scab}, 1, "crop 99 does not exist"},
+		{"unknown pest", []Application{{LabelId: 1}}, Registration{apple, 99}, 0, "pest 99 does not exist"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := testCatalog().Advise(test.applications, test.registration, test.max)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+
+	_, err := testCatalog().Advise([]Application{{LabelId: 1}}, Registration{}, 1)
+	if !errors.Is(err, ErrNoRegistration) {
+		t.Errorf("got error %v, want ErrNoRegistration", err)
+	}
+}